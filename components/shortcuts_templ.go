@@ -0,0 +1,126 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.960
+package components
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+// shortcutsScript binds keyboard shortcuts. Elements opt in with a
+// data-shortcut attribute naming their key: the shortcut clicks them, or
+// focuses them if they are form fields. j and k select the next or previous
+// item card ([data-item]), and the selected card's shortcuts take precedence
+// over the rest of the page's. Since everything is read from attributes when a
+// key is pressed, the shortcuts keep working as htmx swaps content.
+func shortcutsScript() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<script>\n\t\t(() => {\n\t\t\tconst selected = () => document.querySelector(\"[data-item][data-selected]\");\n\n\t\t\tfunction select(item) {\n\t\t\t\tselected()?.removeAttribute(\"data-selected\");\n\t\t\t\tif (!item) {\n\t\t\t\t\treturn;\n\t\t\t\t}\n\t\t\t\titem.setAttribute(\"data-selected\", \"\");\n\t\t\t\titem.scrollIntoView({ block: \"nearest\" });\n\t\t\t}\n\n\t\t\tfunction move(step) {\n\t\t\t\tconst items = [...document.querySelectorAll(\"[data-item]\")];\n\t\t\t\tif (items.length === 0) {\n\t\t\t\t\treturn;\n\t\t\t\t}\n\t\t\t\tconst i = items.indexOf(selected());\n\t\t\t\tconst next = i === -1 ? (step > 0 ? 0 : items.length - 1) : i + step;\n\t\t\t\tselect(items[Math.min(Math.max(next, 0), items.length - 1)]);\n\t\t\t}\n\n\t\t\tfunction shortcutTarget(key) {\n\t\t\t\tconst selector = `[data-shortcut=\"${CSS.escape(key)}\"]`;\n\t\t\t\tconst inItem = selected()?.querySelector(selector);\n\t\t\t\tif (inItem) {\n\t\t\t\t\treturn inItem;\n\t\t\t\t}\n\t\t\t\t// The page's content comes after the header, so its shortcuts win.\n\t\t\t\treturn [...document.querySelectorAll(selector)].filter((el) => !el.closest(\"[data-item]\")).at(-1);\n\t\t\t}\n\n\t\t\tdocument.addEventListener(\"keydown\", (event) => {\n\t\t\t\tif (event.ctrlKey || event.metaKey || event.altKey || event.isComposing) {\n\t\t\t\t\treturn;\n\t\t\t\t}\n\t\t\t\tif (event.target.closest(\"input, textarea, select, [contenteditable]\")) {\n\t\t\t\t\tif (event.key === \"Escape\") {\n\t\t\t\t\t\tevent.target.blur();\n\t\t\t\t\t}\n\t\t\t\t\treturn;\n\t\t\t\t}\n\t\t\t\t// Let Enter activate focused buttons and links as usual.\n\t\t\t\tif (event.key === \"Enter\" && event.target !== document.body) {\n\t\t\t\t\treturn;\n\t\t\t\t}\n\n\t\t\t\tconst key = event.key === \"Enter\" ? \"o\" : event.key;\n\t\t\t\tif (key === \"j\" || key === \"k\") {\n\t\t\t\t\tevent.preventDefault();\n\t\t\t\t\tmove(key === \"j\" ? 1 : -1);\n\t\t\t\t\treturn;\n\t\t\t\t}\n\t\t\t\tif (key === \"?\") {\n\t\t\t\t\tconst dialog = document.getElementById(\"shortcuts\");\n\t\t\t\t\tif (dialog) {\n\t\t\t\t\t\tdialog.open ? dialog.close() : dialog.showModal();\n\t\t\t\t\t}\n\t\t\t\t\treturn;\n\t\t\t\t}\n\n\t\t\t\tconst target = shortcutTarget(key);\n\t\t\t\tif (!target) {\n\t\t\t\t\treturn;\n\t\t\t\t}\n\t\t\t\tevent.preventDefault();\n\t\t\t\tif (target.matches(\"input, textarea, select\")) {\n\t\t\t\t\ttarget.focus();\n\t\t\t\t} else {\n\t\t\t\t\ttarget.click();\n\t\t\t\t}\n\t\t\t});\n\t\t})();\n\t</script>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var shortcuts = []struct {
+	keys        string
+	description string
+}{
+	{"j / k", "Select the next / previous item"},
+	{"o / Enter", "Open the selected item"},
+	{"m", "Mark the selected item as read / unread"},
+	{"s", "Star / unstar the selected item"},
+	{"A", "Mark all as read"},
+	{"r", "Refresh all feeds"},
+	{"/", "Search"},
+	{"u / h / f", "Go to Unread / History / Feeds"},
+	{"?", "Show these shortcuts"},
+}
+
+func shortcutsDialog() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var2 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var2 == nil {
+			templ_7745c5c3_Var2 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<dialog id=\"shortcuts\" class=\"m-auto rounded-md p-5 bg-zinc-800 text-zinc-300 border border-gray-500 backdrop:bg-black/50\"><h2 class=\"text-xl mb-3\">Keyboard shortcuts</h2><table class=\"text-sm mb-3\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, s := range shortcuts {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<tr><td class=\"pr-5 py-1 font-mono whitespace-nowrap\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var3 string
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(s.keys)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/shortcuts.templ`, Line: 111, Col: 63}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</td><td class=\"py-1\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(s.description)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/shortcuts.templ`, Line: 112, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</td></tr>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</table><form method=\"dialog\" class=\"flex justify-end\"><button class=\"rounded-md px-3 border border-gray-500 hover:text-white hover:cursor-pointer\">Close</button></form></dialog> <span hidden hx-post=\"/feeds/refresh\" hx-swap=\"none\" data-shortcut=\"r\"></span>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
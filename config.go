@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+
+	"github.com/ethansaxenian/rss/worker"
 )
 
 type config struct {
-	dsn  string
-	port int
+	dsn           string
+	port          int
+	retention     worker.RetentionPolicy
+	signupEnabled bool
 }
 
 func getConfig() (config, error) {
@@ -22,9 +26,30 @@ func getConfig() (config, error) {
 		return config{}, fmt.Errorf("empty DATABASE_URL") //nolint: err113
 	}
 
+	var retention worker.RetentionPolicy
+	if v, ok := os.LookupEnv("RETENTION_READ_DAYS"); ok {
+		if retention.ReadDays, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return config{}, fmt.Errorf("parsing RETENTION_READ_DAYS: %w", err)
+		}
+	}
+	if v, ok := os.LookupEnv("RETENTION_MAX_ITEMS"); ok {
+		if retention.MaxItems, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return config{}, fmt.Errorf("parsing RETENTION_MAX_ITEMS: %w", err)
+		}
+	}
+
+	var signupEnabled bool
+	if v, ok := os.LookupEnv("SIGNUP_ENABLED"); ok {
+		if signupEnabled, err = strconv.ParseBool(v); err != nil {
+			return config{}, fmt.Errorf("parsing SIGNUP_ENABLED: %w", err)
+		}
+	}
+
 	c := config{
-		dsn:  dsn,
-		port: port,
+		dsn:           dsn,
+		port:          port,
+		retention:     retention,
+		signupEnabled: signupEnabled,
 	}
 
 	return c, nil
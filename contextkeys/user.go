@@ -0,0 +1,23 @@
+package contextkeys
+
+import (
+	"context"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+const ContextKeyUser = contextKey("user")
+
+// GetUserCtx returns the signed-in user, or the zero user if there is none.
+func GetUserCtx(ctx context.Context) database.User {
+	user, ok := ctx.Value(ContextKeyUser).(database.User)
+	if !ok {
+		return database.User{}
+	}
+
+	return user
+}
+
+func WithUserCtx(ctx context.Context, user database.User) context.Context {
+	return context.WithValue(ctx, ContextKeyUser, user)
+}
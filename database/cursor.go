@@ -0,0 +1,85 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ItemOrder is the order of an item list.
+type ItemOrder string
+
+const (
+	ItemOrderNewest  ItemOrder = "newest"  // by published_at, newest first
+	ItemOrderOldest  ItemOrder = "oldest"  // by published_at, oldest first
+	ItemOrderFetched ItemOrder = "fetched" // by created_at, newest first
+)
+
+func AllItemOrderValues() []ItemOrder {
+	return []ItemOrder{
+		ItemOrderNewest,
+		ItemOrderOldest,
+		ItemOrderFetched,
+	}
+}
+
+// ItemCursor is a position in an item list, ordered by (Time, ID) where Time
+// is the column the list is sorted by. A page lists the items after its
+// cursor.
+type ItemCursor struct {
+	Time time.Time
+	ID   int64
+}
+
+// FirstPageCursor comes before every item in a list sorted by order, so it
+// selects the first page.
+func FirstPageCursor(order ItemOrder) ItemCursor {
+	if order == ItemOrderOldest {
+		return ItemCursor{}
+	}
+
+	return ItemCursor{
+		Time: time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		ID:   math.MaxInt64,
+	}
+}
+
+// Cursor returns the cursor of the page after the item, in a list sorted by
+// order.
+func (i UserItem) Cursor(order ItemOrder) ItemCursor {
+	if order == ItemOrderFetched {
+		return ItemCursor{Time: i.CreatedAt, ID: i.ID}
+	}
+
+	return ItemCursor{Time: i.PublishedAt, ID: i.ID}
+}
+
+func (c ItemCursor) String() string {
+	return c.Time.UTC().Format(time.RFC3339Nano) + "_" + strconv.FormatInt(c.ID, 10)
+}
+
+// ParseItemCursor parses a cursor formatted by ItemCursor.String.
+func ParseItemCursor(s string) (ItemCursor, error) {
+	t, id, ok := strings.Cut(s, "_")
+	if !ok {
+		return ItemCursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, s)
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, t)
+	if err != nil {
+		return ItemCursor{}, fmt.Errorf("%w: parsing time: %w", ErrInvalidCursor, err)
+	}
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ItemCursor{}, fmt.Errorf("%w: parsing ID: %w", ErrInvalidCursor, err)
+	}
+
+	// Times are stored in UTC, and compared as text.
+	return ItemCursor{Time: parsed.UTC(), ID: n}, nil
+}
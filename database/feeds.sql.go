@@ -10,8 +10,8 @@ import (
 	"database/sql"
 )
 
-const createFeed = `-- name: CreateFeed :exec
-INSERT INTO feeds(title, url) VALUES (?, ?)
+const createFeed = `-- name: CreateFeed :one
+INSERT INTO feeds(title, url) VALUES (?, ?) RETURNING id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url
 `
 
 type CreateFeedParams struct {
@@ -21,19 +21,84 @@ type CreateFeedParams struct {
 
 // CreateFeed
 //
-//	INSERT INTO feeds(title, url) VALUES (?, ?)
-func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) error {
-	_, err := q.db.ExecContext(ctx, createFeed, arg.Title, arg.URL)
+//	INSERT INTO feeds(title, url) VALUES (?, ?) RETURNING id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url
+func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, createFeed, arg.Title, arg.URL)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.URL,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastRefreshedAt,
+		&i.Image,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastContentLength,
+		&i.BytesSaved,
+		&i.NextRefreshAt,
+		&i.TTLSeconds,
+		&i.IdleRefreshes,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.ConsecutiveFailures,
+		&i.LastHTTPStatus,
+		&i.SiteURL,
+	)
+	return i, err
+}
+
+const createSubscription = `-- name: CreateSubscription :exec
+INSERT INTO subscriptions(user_id, feed_id, title, category_id)
+VALUES (CAST (?1 AS INTEGER), ?2, ?3, ?4)
+`
+
+type CreateSubscriptionParams struct {
+	UserID     int64
+	FeedID     int64
+	Title      string
+	CategoryID sql.NullInt64
+}
+
+// CreateSubscription
+//
+//	INSERT INTO subscriptions(user_id, feed_id, title, category_id)
+//	VALUES (CAST (?1 AS INTEGER), ?2, ?3, ?4)
+func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, createSubscription,
+		arg.UserID,
+		arg.FeedID,
+		arg.Title,
+		arg.CategoryID,
+	)
+	return err
+}
+
+const deleteSubscription = `-- name: DeleteSubscription :exec
+DELETE FROM subscriptions WHERE feed_id = ?1 AND user_id = CAST (?2 AS INTEGER)
+`
+
+type DeleteSubscriptionParams struct {
+	FeedID int64
+	UserID int64
+}
+
+// DeleteSubscription
+//
+//	DELETE FROM subscriptions WHERE feed_id = ?1 AND user_id = CAST (?2 AS INTEGER)
+func (q *Queries) DeleteSubscription(ctx context.Context, arg DeleteSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, deleteSubscription, arg.FeedID, arg.UserID)
 	return err
 }
 
 const getFeed = `-- name: GetFeed :one
-SELECT id, title, url, created_at, updated_at, last_refreshed_at, image FROM feeds WHERE id = ?
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds WHERE id = ?
 `
 
 // GetFeed
 //
-//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image FROM feeds WHERE id = ?
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds WHERE id = ?
 func (q *Queries) GetFeed(ctx context.Context, id int64) (Feed, error) {
 	row := q.db.QueryRowContext(ctx, getFeed, id)
 	var i Feed
@@ -45,19 +110,355 @@ func (q *Queries) GetFeed(ctx context.Context, id int64) (Feed, error) {
 		&i.UpdatedAt,
 		&i.LastRefreshedAt,
 		&i.Image,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastContentLength,
+		&i.BytesSaved,
+		&i.NextRefreshAt,
+		&i.TTLSeconds,
+		&i.IdleRefreshes,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.ConsecutiveFailures,
+		&i.LastHTTPStatus,
+		&i.SiteURL,
+	)
+	return i, err
+}
+
+const getFeedByURL = `-- name: GetFeedByURL :one
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds WHERE url = ?
+`
+
+// GetFeedByURL
+//
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds WHERE url = ?
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.URL,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastRefreshedAt,
+		&i.Image,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastContentLength,
+		&i.BytesSaved,
+		&i.NextRefreshAt,
+		&i.TTLSeconds,
+		&i.IdleRefreshes,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.ConsecutiveFailures,
+		&i.LastHTTPStatus,
+		&i.SiteURL,
 	)
 	return i, err
 }
 
+const getUserFeed = `-- name: GetUserFeed :one
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds WHERE id = ?1 AND user_id = CAST (?2 AS INTEGER)
+`
+
+type GetUserFeedParams struct {
+	ID     int64
+	UserID int64
+}
+
+// GetUserFeed
+//
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds WHERE id = ?1 AND user_id = CAST (?2 AS INTEGER)
+func (q *Queries) GetUserFeed(ctx context.Context, arg GetUserFeedParams) (UserFeed, error) {
+	row := q.db.QueryRowContext(ctx, getUserFeed, arg.ID, arg.UserID)
+	var i UserFeed
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.URL,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastRefreshedAt,
+		&i.Image,
+		&i.CategoryID,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastContentLength,
+		&i.BytesSaved,
+		&i.NextRefreshAt,
+		&i.RefreshIntervalMinutes,
+		&i.TTLSeconds,
+		&i.IdleRefreshes,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.ConsecutiveFailures,
+		&i.LastHTTPStatus,
+		&i.FetchFullContent,
+		&i.RetentionReadDays,
+		&i.RetentionMaxItems,
+		&i.UserID,
+		&i.SiteURL,
+	)
+	return i, err
+}
+
+const getUserFeedByURL = `-- name: GetUserFeedByURL :one
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds WHERE url = ?1 AND user_id = CAST (?2 AS INTEGER)
+`
+
+type GetUserFeedByURLParams struct {
+	URL    string
+	UserID int64
+}
+
+// GetUserFeedByURL
+//
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds WHERE url = ?1 AND user_id = CAST (?2 AS INTEGER)
+func (q *Queries) GetUserFeedByURL(ctx context.Context, arg GetUserFeedByURLParams) (UserFeed, error) {
+	row := q.db.QueryRowContext(ctx, getUserFeedByURL, arg.URL, arg.UserID)
+	var i UserFeed
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.URL,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastRefreshedAt,
+		&i.Image,
+		&i.CategoryID,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastContentLength,
+		&i.BytesSaved,
+		&i.NextRefreshAt,
+		&i.RefreshIntervalMinutes,
+		&i.TTLSeconds,
+		&i.IdleRefreshes,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.ConsecutiveFailures,
+		&i.LastHTTPStatus,
+		&i.FetchFullContent,
+		&i.RetentionReadDays,
+		&i.RetentionMaxItems,
+		&i.UserID,
+		&i.SiteURL,
+	)
+	return i, err
+}
+
+const listAllFeeds = `-- name: ListAllFeeds :many
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds ORDER BY created_at DESC
+`
+
+// ListAllFeeds
+//
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds ORDER BY created_at DESC
+func (q *Queries) ListAllFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listAllFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Feed{}
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.URL,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastRefreshedAt,
+			&i.Image,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastContentLength,
+			&i.BytesSaved,
+			&i.NextRefreshAt,
+			&i.TTLSeconds,
+			&i.IdleRefreshes,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.ConsecutiveFailures,
+			&i.LastHTTPStatus,
+			&i.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBrokenFeeds = `-- name: ListBrokenFeeds :many
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds
+WHERE user_id = CAST (?1 AS INTEGER) AND consecutive_failures > 0
+ORDER BY last_error_at DESC
+`
+
+// ListBrokenFeeds
+//
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds
+//	WHERE user_id = CAST (?1 AS INTEGER) AND consecutive_failures > 0
+//	ORDER BY last_error_at DESC
+func (q *Queries) ListBrokenFeeds(ctx context.Context, userID int64) ([]UserFeed, error) {
+	rows, err := q.db.QueryContext(ctx, listBrokenFeeds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserFeed{}
+	for rows.Next() {
+		var i UserFeed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.URL,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastRefreshedAt,
+			&i.Image,
+			&i.CategoryID,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastContentLength,
+			&i.BytesSaved,
+			&i.NextRefreshAt,
+			&i.RefreshIntervalMinutes,
+			&i.TTLSeconds,
+			&i.IdleRefreshes,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.ConsecutiveFailures,
+			&i.LastHTTPStatus,
+			&i.FetchFullContent,
+			&i.RetentionReadDays,
+			&i.RetentionMaxItems,
+			&i.UserID,
+			&i.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedSubscribers = `-- name: ListFeedSubscribers :many
+SELECT user_id FROM subscriptions WHERE feed_id = ? ORDER BY id
+`
+
+// ListFeedSubscribers
+//
+//	SELECT user_id FROM subscriptions WHERE feed_id = ? ORDER BY id
+func (q *Queries) ListFeedSubscribers(ctx context.Context, feedID int64) ([]sql.NullInt64, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedSubscribers, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []sql.NullInt64{}
+	for rows.Next() {
+		var user_id sql.NullInt64
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listFeeds = `-- name: ListFeeds :many
-SELECT id, title, url, created_at, updated_at, last_refreshed_at, image FROM feeds ORDER BY created_at DESC
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds WHERE user_id = CAST (?1 AS INTEGER) ORDER BY created_at DESC
 `
 
 // ListFeeds
 //
-//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image FROM feeds ORDER BY created_at DESC
-func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, listFeeds)
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, category_id, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, fetch_full_content, retention_read_days, retention_max_items, user_id, site_url FROM user_feeds WHERE user_id = CAST (?1 AS INTEGER) ORDER BY created_at DESC
+func (q *Queries) ListFeeds(ctx context.Context, userID int64) ([]UserFeed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserFeed{}
+	for rows.Next() {
+		var i UserFeed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.URL,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastRefreshedAt,
+			&i.Image,
+			&i.CategoryID,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastContentLength,
+			&i.BytesSaved,
+			&i.NextRefreshAt,
+			&i.RefreshIntervalMinutes,
+			&i.TTLSeconds,
+			&i.IdleRefreshes,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.ConsecutiveFailures,
+			&i.LastHTTPStatus,
+			&i.FetchFullContent,
+			&i.RetentionReadDays,
+			&i.RetentionMaxItems,
+			&i.UserID,
+			&i.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedsDueForRefresh = `-- name: ListFeedsDueForRefresh :many
+SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds
+WHERE next_refresh_at IS NULL OR next_refresh_at <= CURRENT_TIMESTAMP
+ORDER BY next_refresh_at
+`
+
+// ListFeedsDueForRefresh
+//
+//	SELECT id, title, url, created_at, updated_at, last_refreshed_at, image, etag, last_modified, last_content_length, bytes_saved, next_refresh_at, ttl_seconds, idle_refreshes, last_error, last_error_at, consecutive_failures, last_http_status, site_url FROM feeds
+//	WHERE next_refresh_at IS NULL OR next_refresh_at <= CURRENT_TIMESTAMP
+//	ORDER BY next_refresh_at
+func (q *Queries) ListFeedsDueForRefresh(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedsDueForRefresh)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +474,18 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
 			&i.UpdatedAt,
 			&i.LastRefreshedAt,
 			&i.Image,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastContentLength,
+			&i.BytesSaved,
+			&i.NextRefreshAt,
+			&i.TTLSeconds,
+			&i.IdleRefreshes,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.ConsecutiveFailures,
+			&i.LastHTTPStatus,
+			&i.SiteURL,
 		); err != nil {
 			return nil, err
 		}
@@ -87,6 +500,149 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
+const listSubscriptionSettings = `-- name: ListSubscriptionSettings :many
+SELECT refresh_interval_minutes, fetch_full_content, retention_read_days, retention_max_items
+FROM subscriptions
+WHERE feed_id = ?
+`
+
+type ListSubscriptionSettingsRow struct {
+	RefreshIntervalMinutes sql.NullInt64
+	FetchFullContent       bool
+	RetentionReadDays      sql.NullInt64
+	RetentionMaxItems      sql.NullInt64
+}
+
+// ListSubscriptionSettings
+//
+//	SELECT refresh_interval_minutes, fetch_full_content, retention_read_days, retention_max_items
+//	FROM subscriptions
+//	WHERE feed_id = ?
+func (q *Queries) ListSubscriptionSettings(ctx context.Context, feedID int64) ([]ListSubscriptionSettingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSubscriptionSettings, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSubscriptionSettingsRow{}
+	for rows.Next() {
+		var i ListSubscriptionSettingsRow
+		if err := rows.Scan(
+			&i.RefreshIntervalMinutes,
+			&i.FetchFullContent,
+			&i.RetentionReadDays,
+			&i.RetentionMaxItems,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET last_error = ?,
+    last_error_at = CURRENT_TIMESTAMP,
+    consecutive_failures = consecutive_failures + 1,
+    last_http_status = ?
+WHERE id = ?
+`
+
+type RecordFeedFailureParams struct {
+	LastError      sql.NullString
+	LastHTTPStatus sql.NullInt64
+	ID             int64
+}
+
+// RecordFeedFailure
+//
+//	UPDATE feeds
+//	SET last_error = ?,
+//	    last_error_at = CURRENT_TIMESTAMP,
+//	    consecutive_failures = consecutive_failures + 1,
+//	    last_http_status = ?
+//	WHERE id = ?
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFailure, arg.LastError, arg.LastHTTPStatus, arg.ID)
+	return err
+}
+
+const recordFeedNotModified = `-- name: RecordFeedNotModified :exec
+UPDATE feeds
+SET bytes_saved = bytes_saved + last_content_length,
+    last_refreshed_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+// RecordFeedNotModified
+//
+//	UPDATE feeds
+//	SET bytes_saved = bytes_saved + last_content_length,
+//	    last_refreshed_at = CURRENT_TIMESTAMP
+//	WHERE id = ?
+func (q *Queries) RecordFeedNotModified(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, recordFeedNotModified, id)
+	return err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_error = NULL,
+    last_error_at = NULL,
+    consecutive_failures = 0,
+    last_http_status = ?
+WHERE id = ?
+`
+
+type RecordFeedSuccessParams struct {
+	LastHTTPStatus sql.NullInt64
+	ID             int64
+}
+
+// RecordFeedSuccess
+//
+//	UPDATE feeds
+//	SET last_error = NULL,
+//	    last_error_at = NULL,
+//	    consecutive_failures = 0,
+//	    last_http_status = ?
+//	WHERE id = ?
+func (q *Queries) RecordFeedSuccess(ctx context.Context, arg RecordFeedSuccessParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, arg.LastHTTPStatus, arg.ID)
+	return err
+}
+
+const updateFeedCacheHeaders = `-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds SET etag = ?, last_modified = ?, last_content_length = ? WHERE id = ?
+`
+
+type UpdateFeedCacheHeadersParams struct {
+	Etag              sql.NullString
+	LastModified      sql.NullString
+	LastContentLength int64
+	ID                int64
+}
+
+// UpdateFeedCacheHeaders
+//
+//	UPDATE feeds SET etag = ?, last_modified = ?, last_content_length = ? WHERE id = ?
+func (q *Queries) UpdateFeedCacheHeaders(ctx context.Context, arg UpdateFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheHeaders,
+		arg.Etag,
+		arg.LastModified,
+		arg.LastContentLength,
+		arg.ID,
+	)
+	return err
+}
+
 const updateFeedImage = `-- name: UpdateFeedImage :exec
 UPDATE feeds SET image = ? WHERE id = ?
 `
@@ -115,3 +671,105 @@ func (q *Queries) UpdateFeedLastRefreshedAt(ctx context.Context, id int64) error
 	_, err := q.db.ExecContext(ctx, updateFeedLastRefreshedAt, id)
 	return err
 }
+
+const updateFeedSchedule = `-- name: UpdateFeedSchedule :exec
+UPDATE feeds
+SET next_refresh_at = ?, idle_refreshes = ?
+WHERE id = ?
+`
+
+type UpdateFeedScheduleParams struct {
+	NextRefreshAt sql.NullTime
+	IdleRefreshes int64
+	ID            int64
+}
+
+// UpdateFeedSchedule
+//
+//	UPDATE feeds
+//	SET next_refresh_at = ?, idle_refreshes = ?
+//	WHERE id = ?
+func (q *Queries) UpdateFeedSchedule(ctx context.Context, arg UpdateFeedScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedSchedule, arg.NextRefreshAt, arg.IdleRefreshes, arg.ID)
+	return err
+}
+
+const updateFeedSiteURL = `-- name: UpdateFeedSiteURL :exec
+UPDATE feeds SET site_url = ? WHERE id = ?
+`
+
+type UpdateFeedSiteURLParams struct {
+	SiteURL sql.NullString
+	ID      int64
+}
+
+// UpdateFeedSiteURL
+//
+//	UPDATE feeds SET site_url = ? WHERE id = ?
+func (q *Queries) UpdateFeedSiteURL(ctx context.Context, arg UpdateFeedSiteURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedSiteURL, arg.SiteURL, arg.ID)
+	return err
+}
+
+const updateFeedTTL = `-- name: UpdateFeedTTL :exec
+UPDATE feeds SET ttl_seconds = ? WHERE id = ?
+`
+
+type UpdateFeedTTLParams struct {
+	TTLSeconds int64
+	ID         int64
+}
+
+// UpdateFeedTTL
+//
+//	UPDATE feeds SET ttl_seconds = ? WHERE id = ?
+func (q *Queries) UpdateFeedTTL(ctx context.Context, arg UpdateFeedTTLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedTTL, arg.TTLSeconds, arg.ID)
+	return err
+}
+
+const updateSubscription = `-- name: UpdateSubscription :exec
+UPDATE subscriptions
+SET title = ?1,
+    category_id = ?2,
+    refresh_interval_minutes = ?3,
+    fetch_full_content = ?4,
+    retention_read_days = ?5,
+    retention_max_items = ?6
+WHERE feed_id = ?7 AND user_id = CAST (?8 AS INTEGER)
+`
+
+type UpdateSubscriptionParams struct {
+	Title                  string
+	CategoryID             sql.NullInt64
+	RefreshIntervalMinutes sql.NullInt64
+	FetchFullContent       bool
+	RetentionReadDays      sql.NullInt64
+	RetentionMaxItems      sql.NullInt64
+	FeedID                 int64
+	UserID                 int64
+}
+
+// UpdateSubscription
+//
+//	UPDATE subscriptions
+//	SET title = ?1,
+//	    category_id = ?2,
+//	    refresh_interval_minutes = ?3,
+//	    fetch_full_content = ?4,
+//	    retention_read_days = ?5,
+//	    retention_max_items = ?6
+//	WHERE feed_id = ?7 AND user_id = CAST (?8 AS INTEGER)
+func (q *Queries) UpdateSubscription(ctx context.Context, arg UpdateSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, updateSubscription,
+		arg.Title,
+		arg.CategoryID,
+		arg.RefreshIntervalMinutes,
+		arg.FetchFullContent,
+		arg.RetentionReadDays,
+		arg.RetentionMaxItems,
+		arg.FeedID,
+		arg.UserID,
+	)
+	return err
+}
@@ -0,0 +1,30 @@
+package database
+
+import "encoding/json"
+
+// CategoryNames decodes the item's categories, which are stored as a JSON
+// array.
+func (i Item) CategoryNames() []string {
+	return decodeStringList(i.Categories)
+}
+
+// CategoryNames decodes the item's categories, which are stored as a JSON
+// array.
+func (i UserItem) CategoryNames() []string {
+	return decodeStringList(i.Categories)
+}
+
+// TagNames decodes the tags the user's filter rules added to the item, which
+// are stored as a JSON array.
+func (i UserItem) TagNames() []string {
+	return decodeStringList(i.Tags)
+}
+
+func decodeStringList(s string) []string {
+	names := []string{}
+	if err := json.Unmarshal([]byte(s), &names); err != nil {
+		return []string{}
+	}
+
+	return names
+}
@@ -7,11 +7,13 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"time"
 )
 
 const checkItemExists = `-- name: CheckItemExists :one
-SELECT id, feed_id, title, link, description, status, published_at, created_at, updated_at, hash FROM items WHERE feed_id = ? AND hash = ?
+SELECT id, feed_id, title, link, description, published_at, created_at, updated_at, hash, content, author, categories, source_updated_at, extracted_content, extracted_at FROM items WHERE feed_id = ? AND hash = ?
 `
 
 type CheckItemExistsParams struct {
@@ -21,7 +23,7 @@ type CheckItemExistsParams struct {
 
 // CheckItemExists
 //
-//	SELECT id, feed_id, title, link, description, status, published_at, created_at, updated_at, hash FROM items WHERE feed_id = ? AND hash = ?
+//	SELECT id, feed_id, title, link, description, published_at, created_at, updated_at, hash, content, author, categories, source_updated_at, extracted_content, extracted_at FROM items WHERE feed_id = ? AND hash = ?
 func (q *Queries) CheckItemExists(ctx context.Context, arg CheckItemExistsParams) (Item, error) {
 	row := q.db.QueryRowContext(ctx, checkItemExists, arg.FeedID, arg.Hash)
 	var i Item
@@ -31,112 +33,603 @@ func (q *Queries) CheckItemExists(ctx context.Context, arg CheckItemExistsParams
 		&i.Title,
 		&i.Link,
 		&i.Description,
-		&i.Status,
 		&i.PublishedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Hash,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
+		&i.SourceUpdatedAt,
+		&i.ExtractedContent,
+		&i.ExtractedAt,
 	)
 	return i, err
 }
 
+const checkItemTombstoneExists = `-- name: CheckItemTombstoneExists :one
+SELECT EXISTS (SELECT 1 FROM item_tombstones WHERE feed_id = ? AND hash = ?)
+`
+
+type CheckItemTombstoneExistsParams struct {
+	FeedID int64
+	Hash   string
+}
+
+// CheckItemTombstoneExists
+//
+//	SELECT EXISTS (SELECT 1 FROM item_tombstones WHERE feed_id = ? AND hash = ?)
+func (q *Queries) CheckItemTombstoneExists(ctx context.Context, arg CheckItemTombstoneExistsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, checkItemTombstoneExists, arg.FeedID, arg.Hash)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const copyFeedItems = `-- name: CopyFeedItems :exec
+INSERT INTO items(
+  feed_id, title, link, description, content, author, categories, hash,
+  published_at, created_at, source_updated_at, extracted_content, extracted_at
+)
+SELECT
+  CAST (?1 AS INTEGER), title, link, description, content, author, categories, hash,
+  published_at, created_at, source_updated_at, extracted_content, extracted_at
+FROM items WHERE items.feed_id = ?2
+`
+
+type CopyFeedItemsParams struct {
+	NewFeedID int64
+	OldFeedID int64
+}
+
+// CopyFeedItems
+//
+//	INSERT INTO items(
+//	  feed_id, title, link, description, content, author, categories, hash,
+//	  published_at, created_at, source_updated_at, extracted_content, extracted_at
+//	)
+//	SELECT
+//	  CAST (?1 AS INTEGER), title, link, description, content, author, categories, hash,
+//	  published_at, created_at, source_updated_at, extracted_content, extracted_at
+//	FROM items WHERE items.feed_id = ?2
+func (q *Queries) CopyFeedItems(ctx context.Context, arg CopyFeedItemsParams) error {
+	_, err := q.db.ExecContext(ctx, copyFeedItems, arg.NewFeedID, arg.OldFeedID)
+	return err
+}
+
 const countItems = `-- name: CountItems :one
-SELECT COUNT(*) FROM items
-WHERE (CAST (?1 AS BOOL)  = 0 OR items.status  = ?2)
-AND   (CAST (?3 AS BOOL) = 0 OR items.feed_id = ?4)
+SELECT COUNT(*) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL)      = 0 OR user_items.status      = ?3)
+AND   (CAST (?4 AS BOOL)     = 0 OR user_items.feed_id     = ?5)
+AND   (CAST (?6 AS BOOL) = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+AND   (CAST (?8 AS BOOL)    = 0 OR user_items.starred)
 `
 
 type CountItemsParams struct {
-	HasStatus bool
-	Status    Status
-	HasFeedID bool
-	FeedID    int64
+	UserID        int64
+	HasStatus     bool
+	Status        Status
+	HasFeedID     bool
+	FeedID        int64
+	HasCategoryID bool
+	CategoryID    int64
+	StarredOnly   bool
 }
 
 // CountItems
 //
-//	SELECT COUNT(*) FROM items
-//	WHERE (CAST (?1 AS BOOL)  = 0 OR items.status  = ?2)
-//	AND   (CAST (?3 AS BOOL) = 0 OR items.feed_id = ?4)
+//	SELECT COUNT(*) FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL)      = 0 OR user_items.status      = ?3)
+//	AND   (CAST (?4 AS BOOL)     = 0 OR user_items.feed_id     = ?5)
+//	AND   (CAST (?6 AS BOOL) = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+//	AND   (CAST (?8 AS BOOL)    = 0 OR user_items.starred)
 func (q *Queries) CountItems(ctx context.Context, arg CountItemsParams) (int64, error) {
 	row := q.db.QueryRowContext(ctx, countItems,
+		arg.UserID,
 		arg.HasStatus,
 		arg.Status,
 		arg.HasFeedID,
 		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.StarredOnly,
 	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
-const createItem = `-- name: CreateItem :exec
-INSERT INTO items(feed_id, title, link, description, hash, published_at) VALUES (?, ?, ?, ?, ?, ?)
+const createItem = `-- name: CreateItem :one
+INSERT INTO items(feed_id, title, link, description, content, author, categories, hash, published_at, source_updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id
 `
 
 type CreateItemParams struct {
-	FeedID      int64
-	Title       string
-	Link        string
-	Description string
-	Hash        string
-	PublishedAt time.Time
+	FeedID          int64
+	Title           string
+	Link            string
+	Description     string
+	Content         string
+	Author          string
+	Categories      string
+	Hash            string
+	PublishedAt     time.Time
+	SourceUpdatedAt sql.NullTime
 }
 
 // CreateItem
 //
-//	INSERT INTO items(feed_id, title, link, description, hash, published_at) VALUES (?, ?, ?, ?, ?, ?)
-func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) error {
-	_, err := q.db.ExecContext(ctx, createItem,
+//	INSERT INTO items(feed_id, title, link, description, content, author, categories, hash, published_at, source_updated_at)
+//	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+//	RETURNING id
+func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, createItem,
 		arg.FeedID,
 		arg.Title,
 		arg.Link,
 		arg.Description,
+		arg.Content,
+		arg.Author,
+		arg.Categories,
 		arg.Hash,
 		arg.PublishedAt,
+		arg.SourceUpdatedAt,
 	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createItemState = `-- name: CreateItemState :exec
+INSERT INTO item_states(user_id, item_id, status, starred, tags) VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateItemStateParams struct {
+	UserID  sql.NullInt64
+	ItemID  int64
+	Status  Status
+	Starred bool
+	Tags    string
+}
+
+// CreateItemState
+//
+//	INSERT INTO item_states(user_id, item_id, status, starred, tags) VALUES (?, ?, ?, ?, ?)
+func (q *Queries) CreateItemState(ctx context.Context, arg CreateItemStateParams) error {
+	_, err := q.db.ExecContext(ctx, createItemState,
+		arg.UserID,
+		arg.ItemID,
+		arg.Status,
+		arg.Starred,
+		arg.Tags,
+	)
+	return err
+}
+
+const createItemTombstones = `-- name: CreateItemTombstones :exec
+INSERT OR IGNORE INTO item_tombstones (feed_id, hash)
+SELECT feed_id, hash FROM items WHERE id IN (/*SLICE:ids*/?)
+`
+
+// CreateItemTombstones
+//
+//	INSERT OR IGNORE INTO item_tombstones (feed_id, hash)
+//	SELECT feed_id, hash FROM items WHERE id IN (/*SLICE:ids*/?)
+func (q *Queries) CreateItemTombstones(ctx context.Context, ids []int64) error {
+	query := createItemTombstones
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const createSubscriptionItemStates = `-- name: CreateSubscriptionItemStates :exec
+INSERT OR IGNORE INTO item_states(user_id, item_id)
+SELECT CAST (?1 AS INTEGER), items.id FROM items WHERE items.feed_id = ?2
+`
+
+type CreateSubscriptionItemStatesParams struct {
+	UserID int64
+	FeedID int64
+}
+
+// CreateSubscriptionItemStates
+//
+//	INSERT OR IGNORE INTO item_states(user_id, item_id)
+//	SELECT CAST (?1 AS INTEGER), items.id FROM items WHERE items.feed_id = ?2
+func (q *Queries) CreateSubscriptionItemStates(ctx context.Context, arg CreateSubscriptionItemStatesParams) error {
+	_, err := q.db.ExecContext(ctx, createSubscriptionItemStates, arg.UserID, arg.FeedID)
+	return err
+}
+
+const deleteItems = `-- name: DeleteItems :exec
+DELETE FROM items WHERE id IN (/*SLICE:ids*/?)
+`
+
+// DeleteItems
+//
+//	DELETE FROM items WHERE id IN (/*SLICE:ids*/?)
+func (q *Queries) DeleteItems(ctx context.Context, ids []int64) error {
+	query := deleteItems
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
 	return err
 }
 
+const getItem = `-- name: GetItem :one
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.id = ?1 AND user_items.user_id = CAST (?2 AS INTEGER)
+`
+
+type GetItemParams struct {
+	ID     int64
+	UserID int64
+}
+
+type GetItemRow struct {
+	UserItem UserItem
+	UserFeed UserFeed
+}
+
+// GetItem
+//
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.id = ?1 AND user_items.user_id = CAST (?2 AS INTEGER)
+func (q *Queries) GetItem(ctx context.Context, arg GetItemParams) (GetItemRow, error) {
+	row := q.db.QueryRowContext(ctx, getItem, arg.ID, arg.UserID)
+	var i GetItemRow
+	err := row.Scan(
+		&i.UserItem.ID,
+		&i.UserItem.FeedID,
+		&i.UserItem.Title,
+		&i.UserItem.Link,
+		&i.UserItem.Description,
+		&i.UserItem.Status,
+		&i.UserItem.PublishedAt,
+		&i.UserItem.CreatedAt,
+		&i.UserItem.UpdatedAt,
+		&i.UserItem.Hash,
+		&i.UserItem.Starred,
+		&i.UserItem.Content,
+		&i.UserItem.Author,
+		&i.UserItem.Categories,
+		&i.UserItem.SourceUpdatedAt,
+		&i.UserItem.ExtractedContent,
+		&i.UserItem.ExtractedAt,
+		&i.UserItem.Tags,
+		&i.UserItem.UserID,
+		&i.UserFeed.ID,
+		&i.UserFeed.Title,
+		&i.UserFeed.URL,
+		&i.UserFeed.CreatedAt,
+		&i.UserFeed.UpdatedAt,
+		&i.UserFeed.LastRefreshedAt,
+		&i.UserFeed.Image,
+		&i.UserFeed.CategoryID,
+		&i.UserFeed.Etag,
+		&i.UserFeed.LastModified,
+		&i.UserFeed.LastContentLength,
+		&i.UserFeed.BytesSaved,
+		&i.UserFeed.NextRefreshAt,
+		&i.UserFeed.RefreshIntervalMinutes,
+		&i.UserFeed.TTLSeconds,
+		&i.UserFeed.IdleRefreshes,
+		&i.UserFeed.LastError,
+		&i.UserFeed.LastErrorAt,
+		&i.UserFeed.ConsecutiveFailures,
+		&i.UserFeed.LastHTTPStatus,
+		&i.UserFeed.FetchFullContent,
+		&i.UserFeed.RetentionReadDays,
+		&i.UserFeed.RetentionMaxItems,
+		&i.UserFeed.UserID,
+		&i.UserFeed.SiteURL,
+	)
+	return i, err
+}
+
+const listFeedUnreadCounts = `-- name: ListFeedUnreadCounts :many
+SELECT user_items.feed_id, COUNT(*) AS unread_count, CAST (MAX(user_items.published_at) AS TEXT) AS newest_published_at
+FROM user_items
+WHERE user_items.status = "unread" AND user_items.user_id = CAST (?1 AS INTEGER)
+GROUP BY user_items.feed_id
+`
+
+type ListFeedUnreadCountsRow struct {
+	FeedID            int64
+	UnreadCount       int64
+	NewestPublishedAt string
+}
+
+// ListFeedUnreadCounts
+//
+//	SELECT user_items.feed_id, COUNT(*) AS unread_count, CAST (MAX(user_items.published_at) AS TEXT) AS newest_published_at
+//	FROM user_items
+//	WHERE user_items.status = "unread" AND user_items.user_id = CAST (?1 AS INTEGER)
+//	GROUP BY user_items.feed_id
+func (q *Queries) ListFeedUnreadCounts(ctx context.Context, userID int64) ([]ListFeedUnreadCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedUnreadCounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFeedUnreadCountsRow{}
+	for rows.Next() {
+		var i ListFeedUnreadCountsRow
+		if err := rows.Scan(&i.FeedID, &i.UnreadCount, &i.NewestPublishedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemIDs = `-- name: ListItemIDs :many
+SELECT user_items.id FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL)      = 0 OR user_items.status       = ?3)
+AND   (CAST (?4 AS BOOL)     = 0 OR user_items.feed_id      = ?5)
+AND   (CAST (?6 AS BOOL) = 0 OR user_feeds.category_id  = CAST (?7 AS INTEGER))
+AND   (CAST (?8 AS BOOL)    = 0 OR user_items.starred)
+AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at > ?10)
+AND   (CAST (?11 AS BOOL)  = 0 OR user_items.published_at < ?12)
+ORDER BY user_items.published_at DESC, user_items.id DESC
+LIMIT ?14 OFFSET ?13
+`
+
+type ListItemIDsParams struct {
+	UserID        int64
+	HasStatus     bool
+	Status        Status
+	HasFeedID     bool
+	FeedID        int64
+	HasCategoryID bool
+	CategoryID    int64
+	StarredOnly   bool
+	HasNewerThan  bool
+	NewerThan     time.Time
+	HasOlderThan  bool
+	OlderThan     time.Time
+	Offset        int64
+	Limit         int64
+}
+
+// ListItemIDs
+//
+//	SELECT user_items.id FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL)      = 0 OR user_items.status       = ?3)
+//	AND   (CAST (?4 AS BOOL)     = 0 OR user_items.feed_id      = ?5)
+//	AND   (CAST (?6 AS BOOL) = 0 OR user_feeds.category_id  = CAST (?7 AS INTEGER))
+//	AND   (CAST (?8 AS BOOL)    = 0 OR user_items.starred)
+//	AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at > ?10)
+//	AND   (CAST (?11 AS BOOL)  = 0 OR user_items.published_at < ?12)
+//	ORDER BY user_items.published_at DESC, user_items.id DESC
+//	LIMIT ?14 OFFSET ?13
+func (q *Queries) ListItemIDs(ctx context.Context, arg ListItemIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listItemIDs,
+		arg.UserID,
+		arg.HasStatus,
+		arg.Status,
+		arg.HasFeedID,
+		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.HasNewerThan,
+		arg.NewerThan,
+		arg.HasOlderThan,
+		arg.OlderThan,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemIDsOldestFirst = `-- name: ListItemIDsOldestFirst :many
+SELECT user_items.id FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL)      = 0 OR user_items.status       = ?3)
+AND   (CAST (?4 AS BOOL)     = 0 OR user_items.feed_id      = ?5)
+AND   (CAST (?6 AS BOOL) = 0 OR user_feeds.category_id  = CAST (?7 AS INTEGER))
+AND   (CAST (?8 AS BOOL)    = 0 OR user_items.starred)
+AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at > ?10)
+AND   (CAST (?11 AS BOOL)  = 0 OR user_items.published_at < ?12)
+ORDER BY user_items.published_at ASC, user_items.id ASC
+LIMIT ?14 OFFSET ?13
+`
+
+type ListItemIDsOldestFirstParams struct {
+	UserID        int64
+	HasStatus     bool
+	Status        Status
+	HasFeedID     bool
+	FeedID        int64
+	HasCategoryID bool
+	CategoryID    int64
+	StarredOnly   bool
+	HasNewerThan  bool
+	NewerThan     time.Time
+	HasOlderThan  bool
+	OlderThan     time.Time
+	Offset        int64
+	Limit         int64
+}
+
+// ListItemIDsOldestFirst
+//
+//	SELECT user_items.id FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL)      = 0 OR user_items.status       = ?3)
+//	AND   (CAST (?4 AS BOOL)     = 0 OR user_items.feed_id      = ?5)
+//	AND   (CAST (?6 AS BOOL) = 0 OR user_feeds.category_id  = CAST (?7 AS INTEGER))
+//	AND   (CAST (?8 AS BOOL)    = 0 OR user_items.starred)
+//	AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at > ?10)
+//	AND   (CAST (?11 AS BOOL)  = 0 OR user_items.published_at < ?12)
+//	ORDER BY user_items.published_at ASC, user_items.id ASC
+//	LIMIT ?14 OFFSET ?13
+func (q *Queries) ListItemIDsOldestFirst(ctx context.Context, arg ListItemIDsOldestFirstParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listItemIDsOldestFirst,
+		arg.UserID,
+		arg.HasStatus,
+		arg.Status,
+		arg.HasFeedID,
+		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.HasNewerThan,
+		arg.NewerThan,
+		arg.HasOlderThan,
+		arg.OlderThan,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listItems = `-- name: ListItems :many
-SELECT items.id, items.feed_id, items.title, items.link, items.description, items.status, items.published_at, items.created_at, items.updated_at, items.hash, feeds.id, feeds.title, feeds.url, feeds.created_at, feeds.updated_at, feeds.last_refreshed_at, feeds.image FROM items
-JOIN feeds ON items.feed_id = feeds.id
-WHERE (CAST (? AS BOOL)  = 0 OR items.status  = ?)
-AND   (CAST (? AS BOOL) = 0 OR items.feed_id = ?)
-ORDER BY items.published_at DESC
-LIMIT ? OFFSET ?
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL)           = 0 OR user_items.status      = ?3)
+AND   (CAST (?4 AS BOOL)          = 0 OR user_items.feed_id     = ?5)
+AND   (CAST (?6 AS BOOL)      = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+AND   (CAST (?8 AS BOOL)         = 0 OR user_items.starred)
+AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at >= ?10)
+AND   (CAST (?11 AS BOOL) = 0 OR user_items.published_at < ?12)
+AND   (CAST (?13 AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (?13 AS TEXT))) > 0)
+AND   user_items.published_at <= ?14
+AND   (user_items.published_at < ?14 OR user_items.id < ?15)
+ORDER BY user_items.published_at DESC, user_items.id DESC
+LIMIT ?16
 `
 
 type ListItemsParams struct {
-	HasStatus bool
-	Status    Status
-	HasFeedID bool
-	FeedID    int64
-	Limit     int64
-	Offset    int64
+	UserID             int64
+	HasStatus          bool
+	Status             Status
+	HasFeedID          bool
+	FeedID             int64
+	HasCategoryID      bool
+	CategoryID         int64
+	StarredOnly        bool
+	HasPublishedAfter  bool
+	PublishedAfter     time.Time
+	HasPublishedBefore bool
+	PublishedBefore    time.Time
+	Author             string
+	CursorPublishedAt  time.Time
+	CursorID           int64
+	Limit              int64
 }
 
 type ListItemsRow struct {
-	Item Item
-	Feed Feed
+	UserItem UserItem
+	UserFeed UserFeed
 }
 
 // ListItems
 //
-//	SELECT items.id, items.feed_id, items.title, items.link, items.description, items.status, items.published_at, items.created_at, items.updated_at, items.hash, feeds.id, feeds.title, feeds.url, feeds.created_at, feeds.updated_at, feeds.last_refreshed_at, feeds.image FROM items
-//	JOIN feeds ON items.feed_id = feeds.id
-//	WHERE (CAST (? AS BOOL)  = 0 OR items.status  = ?)
-//	AND   (CAST (? AS BOOL) = 0 OR items.feed_id = ?)
-//	ORDER BY items.published_at DESC
-//	LIMIT ? OFFSET ?
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL)           = 0 OR user_items.status      = ?3)
+//	AND   (CAST (?4 AS BOOL)          = 0 OR user_items.feed_id     = ?5)
+//	AND   (CAST (?6 AS BOOL)      = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+//	AND   (CAST (?8 AS BOOL)         = 0 OR user_items.starred)
+//	AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at >= ?10)
+//	AND   (CAST (?11 AS BOOL) = 0 OR user_items.published_at < ?12)
+//	AND   (CAST (?13 AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (?13 AS TEXT))) > 0)
+//	AND   user_items.published_at <= ?14
+//	AND   (user_items.published_at < ?14 OR user_items.id < ?15)
+//	ORDER BY user_items.published_at DESC, user_items.id DESC
+//	LIMIT ?16
 func (q *Queries) ListItems(ctx context.Context, arg ListItemsParams) ([]ListItemsRow, error) {
 	rows, err := q.db.QueryContext(ctx, listItems,
+		arg.UserID,
 		arg.HasStatus,
 		arg.Status,
 		arg.HasFeedID,
 		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.HasPublishedAfter,
+		arg.PublishedAfter,
+		arg.HasPublishedBefore,
+		arg.PublishedBefore,
+		arg.Author,
+		arg.CursorPublishedAt,
+		arg.CursorID,
 		arg.Limit,
-		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -146,23 +639,50 @@ func (q *Queries) ListItems(ctx context.Context, arg ListItemsParams) ([]ListIte
 	for rows.Next() {
 		var i ListItemsRow
 		if err := rows.Scan(
-			&i.Item.ID,
-			&i.Item.FeedID,
-			&i.Item.Title,
-			&i.Item.Link,
-			&i.Item.Description,
-			&i.Item.Status,
-			&i.Item.PublishedAt,
-			&i.Item.CreatedAt,
-			&i.Item.UpdatedAt,
-			&i.Item.Hash,
-			&i.Feed.ID,
-			&i.Feed.Title,
-			&i.Feed.URL,
-			&i.Feed.CreatedAt,
-			&i.Feed.UpdatedAt,
-			&i.Feed.LastRefreshedAt,
-			&i.Feed.Image,
+			&i.UserItem.ID,
+			&i.UserItem.FeedID,
+			&i.UserItem.Title,
+			&i.UserItem.Link,
+			&i.UserItem.Description,
+			&i.UserItem.Status,
+			&i.UserItem.PublishedAt,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.UserItem.Hash,
+			&i.UserItem.Starred,
+			&i.UserItem.Content,
+			&i.UserItem.Author,
+			&i.UserItem.Categories,
+			&i.UserItem.SourceUpdatedAt,
+			&i.UserItem.ExtractedContent,
+			&i.UserItem.ExtractedAt,
+			&i.UserItem.Tags,
+			&i.UserItem.UserID,
+			&i.UserFeed.ID,
+			&i.UserFeed.Title,
+			&i.UserFeed.URL,
+			&i.UserFeed.CreatedAt,
+			&i.UserFeed.UpdatedAt,
+			&i.UserFeed.LastRefreshedAt,
+			&i.UserFeed.Image,
+			&i.UserFeed.CategoryID,
+			&i.UserFeed.Etag,
+			&i.UserFeed.LastModified,
+			&i.UserFeed.LastContentLength,
+			&i.UserFeed.BytesSaved,
+			&i.UserFeed.NextRefreshAt,
+			&i.UserFeed.RefreshIntervalMinutes,
+			&i.UserFeed.TTLSeconds,
+			&i.UserFeed.IdleRefreshes,
+			&i.UserFeed.LastError,
+			&i.UserFeed.LastErrorAt,
+			&i.UserFeed.ConsecutiveFailures,
+			&i.UserFeed.LastHTTPStatus,
+			&i.UserFeed.FetchFullContent,
+			&i.UserFeed.RetentionReadDays,
+			&i.UserFeed.RetentionMaxItems,
+			&i.UserFeed.UserID,
+			&i.UserFeed.SiteURL,
 		); err != nil {
 			return nil, err
 		}
@@ -177,70 +697,1083 @@ func (q *Queries) ListItems(ctx context.Context, arg ListItemsParams) ([]ListIte
 	return items, nil
 }
 
-const markAllItemsAsRead = `-- name: MarkAllItemsAsRead :exec
-UPDATE items SET status = "read" WHERE status = "unread"
+const listItemsAfterID = `-- name: ListItemsAfterID :many
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.id > ?1 AND user_items.user_id = CAST (?2 AS INTEGER)
+ORDER BY user_items.id
+LIMIT ?3
 `
 
-// MarkAllItemsAsRead
+type ListItemsAfterIDParams struct {
+	ID     int64
+	UserID int64
+	Limit  int64
+}
+
+type ListItemsAfterIDRow struct {
+	UserItem UserItem
+	UserFeed UserFeed
+}
+
+// ListItemsAfterID
 //
-//	UPDATE items SET status = "read" WHERE status = "unread"
-func (q *Queries) MarkAllItemsAsRead(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, markAllItemsAsRead)
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.id > ?1 AND user_items.user_id = CAST (?2 AS INTEGER)
+//	ORDER BY user_items.id
+//	LIMIT ?3
+func (q *Queries) ListItemsAfterID(ctx context.Context, arg ListItemsAfterIDParams) ([]ListItemsAfterIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsAfterID, arg.ID, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListItemsAfterIDRow{}
+	for rows.Next() {
+		var i ListItemsAfterIDRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.FeedID,
+			&i.UserItem.Title,
+			&i.UserItem.Link,
+			&i.UserItem.Description,
+			&i.UserItem.Status,
+			&i.UserItem.PublishedAt,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.UserItem.Hash,
+			&i.UserItem.Starred,
+			&i.UserItem.Content,
+			&i.UserItem.Author,
+			&i.UserItem.Categories,
+			&i.UserItem.SourceUpdatedAt,
+			&i.UserItem.ExtractedContent,
+			&i.UserItem.ExtractedAt,
+			&i.UserItem.Tags,
+			&i.UserItem.UserID,
+			&i.UserFeed.ID,
+			&i.UserFeed.Title,
+			&i.UserFeed.URL,
+			&i.UserFeed.CreatedAt,
+			&i.UserFeed.UpdatedAt,
+			&i.UserFeed.LastRefreshedAt,
+			&i.UserFeed.Image,
+			&i.UserFeed.CategoryID,
+			&i.UserFeed.Etag,
+			&i.UserFeed.LastModified,
+			&i.UserFeed.LastContentLength,
+			&i.UserFeed.BytesSaved,
+			&i.UserFeed.NextRefreshAt,
+			&i.UserFeed.RefreshIntervalMinutes,
+			&i.UserFeed.TTLSeconds,
+			&i.UserFeed.IdleRefreshes,
+			&i.UserFeed.LastError,
+			&i.UserFeed.LastErrorAt,
+			&i.UserFeed.ConsecutiveFailures,
+			&i.UserFeed.LastHTTPStatus,
+			&i.UserFeed.FetchFullContent,
+			&i.UserFeed.RetentionReadDays,
+			&i.UserFeed.RetentionMaxItems,
+			&i.UserFeed.UserID,
+			&i.UserFeed.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsBeforeID = `-- name: ListItemsBeforeID :many
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.id < ?1 AND user_items.user_id = CAST (?2 AS INTEGER)
+ORDER BY user_items.id DESC
+LIMIT ?3
+`
+
+type ListItemsBeforeIDParams struct {
+	ID     int64
+	UserID int64
+	Limit  int64
+}
+
+type ListItemsBeforeIDRow struct {
+	UserItem UserItem
+	UserFeed UserFeed
+}
+
+// ListItemsBeforeID
+//
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.id < ?1 AND user_items.user_id = CAST (?2 AS INTEGER)
+//	ORDER BY user_items.id DESC
+//	LIMIT ?3
+func (q *Queries) ListItemsBeforeID(ctx context.Context, arg ListItemsBeforeIDParams) ([]ListItemsBeforeIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsBeforeID, arg.ID, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListItemsBeforeIDRow{}
+	for rows.Next() {
+		var i ListItemsBeforeIDRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.FeedID,
+			&i.UserItem.Title,
+			&i.UserItem.Link,
+			&i.UserItem.Description,
+			&i.UserItem.Status,
+			&i.UserItem.PublishedAt,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.UserItem.Hash,
+			&i.UserItem.Starred,
+			&i.UserItem.Content,
+			&i.UserItem.Author,
+			&i.UserItem.Categories,
+			&i.UserItem.SourceUpdatedAt,
+			&i.UserItem.ExtractedContent,
+			&i.UserItem.ExtractedAt,
+			&i.UserItem.Tags,
+			&i.UserItem.UserID,
+			&i.UserFeed.ID,
+			&i.UserFeed.Title,
+			&i.UserFeed.URL,
+			&i.UserFeed.CreatedAt,
+			&i.UserFeed.UpdatedAt,
+			&i.UserFeed.LastRefreshedAt,
+			&i.UserFeed.Image,
+			&i.UserFeed.CategoryID,
+			&i.UserFeed.Etag,
+			&i.UserFeed.LastModified,
+			&i.UserFeed.LastContentLength,
+			&i.UserFeed.BytesSaved,
+			&i.UserFeed.NextRefreshAt,
+			&i.UserFeed.RefreshIntervalMinutes,
+			&i.UserFeed.TTLSeconds,
+			&i.UserFeed.IdleRefreshes,
+			&i.UserFeed.LastError,
+			&i.UserFeed.LastErrorAt,
+			&i.UserFeed.ConsecutiveFailures,
+			&i.UserFeed.LastHTTPStatus,
+			&i.UserFeed.FetchFullContent,
+			&i.UserFeed.RetentionReadDays,
+			&i.UserFeed.RetentionMaxItems,
+			&i.UserFeed.UserID,
+			&i.UserFeed.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsByFetchedAt = `-- name: ListItemsByFetchedAt :many
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL)           = 0 OR user_items.status      = ?3)
+AND   (CAST (?4 AS BOOL)          = 0 OR user_items.feed_id     = ?5)
+AND   (CAST (?6 AS BOOL)      = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+AND   (CAST (?8 AS BOOL)         = 0 OR user_items.starred)
+AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at >= ?10)
+AND   (CAST (?11 AS BOOL) = 0 OR user_items.published_at < ?12)
+AND   (CAST (?13 AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (?13 AS TEXT))) > 0)
+AND   user_items.created_at <= CAST (?14 AS TEXT)
+AND   (user_items.created_at < CAST (?14 AS TEXT) OR user_items.id < ?15)
+ORDER BY user_items.created_at DESC, user_items.id DESC
+LIMIT ?16
+`
+
+type ListItemsByFetchedAtParams struct {
+	UserID             int64
+	HasStatus          bool
+	Status             Status
+	HasFeedID          bool
+	FeedID             int64
+	HasCategoryID      bool
+	CategoryID         int64
+	StarredOnly        bool
+	HasPublishedAfter  bool
+	PublishedAfter     time.Time
+	HasPublishedBefore bool
+	PublishedBefore    time.Time
+	Author             string
+	CursorCreatedAt    string
+	CursorID           int64
+	Limit              int64
+}
+
+type ListItemsByFetchedAtRow struct {
+	UserItem UserItem
+	UserFeed UserFeed
+}
+
+// ListItemsByFetchedAt
+//
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL)           = 0 OR user_items.status      = ?3)
+//	AND   (CAST (?4 AS BOOL)          = 0 OR user_items.feed_id     = ?5)
+//	AND   (CAST (?6 AS BOOL)      = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+//	AND   (CAST (?8 AS BOOL)         = 0 OR user_items.starred)
+//	AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at >= ?10)
+//	AND   (CAST (?11 AS BOOL) = 0 OR user_items.published_at < ?12)
+//	AND   (CAST (?13 AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (?13 AS TEXT))) > 0)
+//	AND   user_items.created_at <= CAST (?14 AS TEXT)
+//	AND   (user_items.created_at < CAST (?14 AS TEXT) OR user_items.id < ?15)
+//	ORDER BY user_items.created_at DESC, user_items.id DESC
+//	LIMIT ?16
+func (q *Queries) ListItemsByFetchedAt(ctx context.Context, arg ListItemsByFetchedAtParams) ([]ListItemsByFetchedAtRow, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsByFetchedAt,
+		arg.UserID,
+		arg.HasStatus,
+		arg.Status,
+		arg.HasFeedID,
+		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.HasPublishedAfter,
+		arg.PublishedAfter,
+		arg.HasPublishedBefore,
+		arg.PublishedBefore,
+		arg.Author,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListItemsByFetchedAtRow{}
+	for rows.Next() {
+		var i ListItemsByFetchedAtRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.FeedID,
+			&i.UserItem.Title,
+			&i.UserItem.Link,
+			&i.UserItem.Description,
+			&i.UserItem.Status,
+			&i.UserItem.PublishedAt,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.UserItem.Hash,
+			&i.UserItem.Starred,
+			&i.UserItem.Content,
+			&i.UserItem.Author,
+			&i.UserItem.Categories,
+			&i.UserItem.SourceUpdatedAt,
+			&i.UserItem.ExtractedContent,
+			&i.UserItem.ExtractedAt,
+			&i.UserItem.Tags,
+			&i.UserItem.UserID,
+			&i.UserFeed.ID,
+			&i.UserFeed.Title,
+			&i.UserFeed.URL,
+			&i.UserFeed.CreatedAt,
+			&i.UserFeed.UpdatedAt,
+			&i.UserFeed.LastRefreshedAt,
+			&i.UserFeed.Image,
+			&i.UserFeed.CategoryID,
+			&i.UserFeed.Etag,
+			&i.UserFeed.LastModified,
+			&i.UserFeed.LastContentLength,
+			&i.UserFeed.BytesSaved,
+			&i.UserFeed.NextRefreshAt,
+			&i.UserFeed.RefreshIntervalMinutes,
+			&i.UserFeed.TTLSeconds,
+			&i.UserFeed.IdleRefreshes,
+			&i.UserFeed.LastError,
+			&i.UserFeed.LastErrorAt,
+			&i.UserFeed.ConsecutiveFailures,
+			&i.UserFeed.LastHTTPStatus,
+			&i.UserFeed.FetchFullContent,
+			&i.UserFeed.RetentionReadDays,
+			&i.UserFeed.RetentionMaxItems,
+			&i.UserFeed.UserID,
+			&i.UserFeed.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsByID = `-- name: ListItemsByID :many
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER) AND user_items.id IN (/*SLICE:ids*/?)
+`
+
+type ListItemsByIDParams struct {
+	UserID int64
+	Ids    []int64
+}
+
+type ListItemsByIDRow struct {
+	UserItem UserItem
+	UserFeed UserFeed
+}
+
+// ListItemsByID
+//
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER) AND user_items.id IN (/*SLICE:ids*/?)
+func (q *Queries) ListItemsByID(ctx context.Context, arg ListItemsByIDParams) ([]ListItemsByIDRow, error) {
+	query := listItemsByID
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListItemsByIDRow{}
+	for rows.Next() {
+		var i ListItemsByIDRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.FeedID,
+			&i.UserItem.Title,
+			&i.UserItem.Link,
+			&i.UserItem.Description,
+			&i.UserItem.Status,
+			&i.UserItem.PublishedAt,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.UserItem.Hash,
+			&i.UserItem.Starred,
+			&i.UserItem.Content,
+			&i.UserItem.Author,
+			&i.UserItem.Categories,
+			&i.UserItem.SourceUpdatedAt,
+			&i.UserItem.ExtractedContent,
+			&i.UserItem.ExtractedAt,
+			&i.UserItem.Tags,
+			&i.UserItem.UserID,
+			&i.UserFeed.ID,
+			&i.UserFeed.Title,
+			&i.UserFeed.URL,
+			&i.UserFeed.CreatedAt,
+			&i.UserFeed.UpdatedAt,
+			&i.UserFeed.LastRefreshedAt,
+			&i.UserFeed.Image,
+			&i.UserFeed.CategoryID,
+			&i.UserFeed.Etag,
+			&i.UserFeed.LastModified,
+			&i.UserFeed.LastContentLength,
+			&i.UserFeed.BytesSaved,
+			&i.UserFeed.NextRefreshAt,
+			&i.UserFeed.RefreshIntervalMinutes,
+			&i.UserFeed.TTLSeconds,
+			&i.UserFeed.IdleRefreshes,
+			&i.UserFeed.LastError,
+			&i.UserFeed.LastErrorAt,
+			&i.UserFeed.ConsecutiveFailures,
+			&i.UserFeed.LastHTTPStatus,
+			&i.UserFeed.FetchFullContent,
+			&i.UserFeed.RetentionReadDays,
+			&i.UserFeed.RetentionMaxItems,
+			&i.UserFeed.UserID,
+			&i.UserFeed.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsOldestFirst = `-- name: ListItemsOldestFirst :many
+SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL)           = 0 OR user_items.status      = ?3)
+AND   (CAST (?4 AS BOOL)          = 0 OR user_items.feed_id     = ?5)
+AND   (CAST (?6 AS BOOL)      = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+AND   (CAST (?8 AS BOOL)         = 0 OR user_items.starred)
+AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at >= ?10)
+AND   (CAST (?11 AS BOOL) = 0 OR user_items.published_at < ?12)
+AND   (CAST (?13 AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (?13 AS TEXT))) > 0)
+AND   user_items.published_at >= ?14
+AND   (user_items.published_at > ?14 OR user_items.id > ?15)
+ORDER BY user_items.published_at ASC, user_items.id ASC
+LIMIT ?16
+`
+
+type ListItemsOldestFirstParams struct {
+	UserID             int64
+	HasStatus          bool
+	Status             Status
+	HasFeedID          bool
+	FeedID             int64
+	HasCategoryID      bool
+	CategoryID         int64
+	StarredOnly        bool
+	HasPublishedAfter  bool
+	PublishedAfter     time.Time
+	HasPublishedBefore bool
+	PublishedBefore    time.Time
+	Author             string
+	CursorPublishedAt  time.Time
+	CursorID           int64
+	Limit              int64
+}
+
+type ListItemsOldestFirstRow struct {
+	UserItem UserItem
+	UserFeed UserFeed
+}
+
+// ListItemsOldestFirst
+//
+//	SELECT user_items.id, user_items.feed_id, user_items.title, user_items.link, user_items.description, user_items.status, user_items.published_at, user_items.created_at, user_items.updated_at, user_items.hash, user_items.starred, user_items.content, user_items.author, user_items.categories, user_items.source_updated_at, user_items.extracted_content, user_items.extracted_at, user_items.tags, user_items.user_id, user_feeds.id, user_feeds.title, user_feeds.url, user_feeds.created_at, user_feeds.updated_at, user_feeds.last_refreshed_at, user_feeds.image, user_feeds.category_id, user_feeds.etag, user_feeds.last_modified, user_feeds.last_content_length, user_feeds.bytes_saved, user_feeds.next_refresh_at, user_feeds.refresh_interval_minutes, user_feeds.ttl_seconds, user_feeds.idle_refreshes, user_feeds.last_error, user_feeds.last_error_at, user_feeds.consecutive_failures, user_feeds.last_http_status, user_feeds.fetch_full_content, user_feeds.retention_read_days, user_feeds.retention_max_items, user_feeds.user_id, user_feeds.site_url FROM user_items
+//	JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+//	WHERE user_items.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL)           = 0 OR user_items.status      = ?3)
+//	AND   (CAST (?4 AS BOOL)          = 0 OR user_items.feed_id     = ?5)
+//	AND   (CAST (?6 AS BOOL)      = 0 OR user_feeds.category_id = CAST (?7 AS INTEGER))
+//	AND   (CAST (?8 AS BOOL)         = 0 OR user_items.starred)
+//	AND   (CAST (?9 AS BOOL)  = 0 OR user_items.published_at >= ?10)
+//	AND   (CAST (?11 AS BOOL) = 0 OR user_items.published_at < ?12)
+//	AND   (CAST (?13 AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (?13 AS TEXT))) > 0)
+//	AND   user_items.published_at >= ?14
+//	AND   (user_items.published_at > ?14 OR user_items.id > ?15)
+//	ORDER BY user_items.published_at ASC, user_items.id ASC
+//	LIMIT ?16
+func (q *Queries) ListItemsOldestFirst(ctx context.Context, arg ListItemsOldestFirstParams) ([]ListItemsOldestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsOldestFirst,
+		arg.UserID,
+		arg.HasStatus,
+		arg.Status,
+		arg.HasFeedID,
+		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.HasPublishedAfter,
+		arg.PublishedAfter,
+		arg.HasPublishedBefore,
+		arg.PublishedBefore,
+		arg.Author,
+		arg.CursorPublishedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListItemsOldestFirstRow{}
+	for rows.Next() {
+		var i ListItemsOldestFirstRow
+		if err := rows.Scan(
+			&i.UserItem.ID,
+			&i.UserItem.FeedID,
+			&i.UserItem.Title,
+			&i.UserItem.Link,
+			&i.UserItem.Description,
+			&i.UserItem.Status,
+			&i.UserItem.PublishedAt,
+			&i.UserItem.CreatedAt,
+			&i.UserItem.UpdatedAt,
+			&i.UserItem.Hash,
+			&i.UserItem.Starred,
+			&i.UserItem.Content,
+			&i.UserItem.Author,
+			&i.UserItem.Categories,
+			&i.UserItem.SourceUpdatedAt,
+			&i.UserItem.ExtractedContent,
+			&i.UserItem.ExtractedAt,
+			&i.UserItem.Tags,
+			&i.UserItem.UserID,
+			&i.UserFeed.ID,
+			&i.UserFeed.Title,
+			&i.UserFeed.URL,
+			&i.UserFeed.CreatedAt,
+			&i.UserFeed.UpdatedAt,
+			&i.UserFeed.LastRefreshedAt,
+			&i.UserFeed.Image,
+			&i.UserFeed.CategoryID,
+			&i.UserFeed.Etag,
+			&i.UserFeed.LastModified,
+			&i.UserFeed.LastContentLength,
+			&i.UserFeed.BytesSaved,
+			&i.UserFeed.NextRefreshAt,
+			&i.UserFeed.RefreshIntervalMinutes,
+			&i.UserFeed.TTLSeconds,
+			&i.UserFeed.IdleRefreshes,
+			&i.UserFeed.LastError,
+			&i.UserFeed.LastErrorAt,
+			&i.UserFeed.ConsecutiveFailures,
+			&i.UserFeed.LastHTTPStatus,
+			&i.UserFeed.FetchFullContent,
+			&i.UserFeed.RetentionReadDays,
+			&i.UserFeed.RetentionMaxItems,
+			&i.UserFeed.UserID,
+			&i.UserFeed.SiteURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsPastRetention = `-- name: ListItemsPastRetention :many
+SELECT items.id FROM items
+WHERE items.feed_id = ?1
+AND   NOT EXISTS (SELECT 1 FROM item_states WHERE item_states.item_id = items.id AND item_states.starred)
+AND   (
+    (CAST (?2 AS BOOL) = 1 AND items.id NOT IN (
+        SELECT item_states.item_id FROM item_states
+        WHERE item_states.item_id = items.id
+        AND   (item_states.status = "unread" OR item_states.read_at >= CAST (?3 AS TEXT))
+    ))
+    OR (CAST (?4 AS BOOL) = 1 AND items.id NOT IN (
+        SELECT newest.id FROM items AS newest
+        WHERE newest.feed_id = ?1
+        ORDER BY newest.published_at DESC, newest.id DESC
+        LIMIT ?5
+    ))
+)
+`
+
+type ListItemsPastRetentionParams struct {
+	FeedID        int64
+	HasReadCutoff bool
+	ReadCutoff    string
+	HasMaxItems   bool
+	MaxItems      int64
+}
+
+// ListItemsPastRetention
+//
+//	SELECT items.id FROM items
+//	WHERE items.feed_id = ?1
+//	AND   NOT EXISTS (SELECT 1 FROM item_states WHERE item_states.item_id = items.id AND item_states.starred)
+//	AND   (
+//	    (CAST (?2 AS BOOL) = 1 AND items.id NOT IN (
+//	        SELECT item_states.item_id FROM item_states
+//	        WHERE item_states.item_id = items.id
+//	        AND   (item_states.status = "unread" OR item_states.read_at >= CAST (?3 AS TEXT))
+//	    ))
+//	    OR (CAST (?4 AS BOOL) = 1 AND items.id NOT IN (
+//	        SELECT newest.id FROM items AS newest
+//	        WHERE newest.feed_id = ?1
+//	        ORDER BY newest.published_at DESC, newest.id DESC
+//	        LIMIT ?5
+//	    ))
+//	)
+func (q *Queries) ListItemsPastRetention(ctx context.Context, arg ListItemsPastRetentionParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsPastRetention,
+		arg.FeedID,
+		arg.HasReadCutoff,
+		arg.ReadCutoff,
+		arg.HasMaxItems,
+		arg.MaxItems,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsToExtract = `-- name: ListItemsToExtract :many
+SELECT id, feed_id, title, link, description, published_at, created_at, updated_at, hash, content, author, categories, source_updated_at, extracted_content, extracted_at FROM items
+WHERE feed_id = ? AND extracted_at IS NULL AND link != ''
+ORDER BY published_at DESC
+LIMIT ?
+`
+
+type ListItemsToExtractParams struct {
+	FeedID int64
+	Limit  int64
+}
+
+// ListItemsToExtract
+//
+//	SELECT id, feed_id, title, link, description, published_at, created_at, updated_at, hash, content, author, categories, source_updated_at, extracted_content, extracted_at FROM items
+//	WHERE feed_id = ? AND extracted_at IS NULL AND link != ''
+//	ORDER BY published_at DESC
+//	LIMIT ?
+func (q *Queries) ListItemsToExtract(ctx context.Context, arg ListItemsToExtractParams) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsToExtract, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Title,
+			&i.Link,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Hash,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.SourceUpdatedAt,
+			&i.ExtractedContent,
+			&i.ExtractedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchingItemStates = `-- name: ListMatchingItemStates :many
+SELECT new_items.id AS item_id, item_states.status, item_states.starred, item_states.tags, item_states.read_at
+FROM item_states
+JOIN items AS old_items ON old_items.id = item_states.item_id
+JOIN items AS new_items ON new_items.hash = old_items.hash OR (old_items.link != '' AND new_items.link = old_items.link)
+WHERE item_states.user_id = CAST (?1 AS INTEGER)
+AND   old_items.feed_id = CAST (?2 AS INTEGER)
+AND   new_items.feed_id = CAST (?3 AS INTEGER)
+`
+
+type ListMatchingItemStatesParams struct {
+	UserID    int64
+	OldFeedID int64
+	NewFeedID int64
+}
+
+type ListMatchingItemStatesRow struct {
+	ItemID  int64
+	Status  Status
+	Starred bool
+	Tags    string
+	ReadAt  sql.NullTime
+}
+
+// ListMatchingItemStates
+//
+//	SELECT new_items.id AS item_id, item_states.status, item_states.starred, item_states.tags, item_states.read_at
+//	FROM item_states
+//	JOIN items AS old_items ON old_items.id = item_states.item_id
+//	JOIN items AS new_items ON new_items.hash = old_items.hash OR (old_items.link != '' AND new_items.link = old_items.link)
+//	WHERE item_states.user_id = CAST (?1 AS INTEGER)
+//	AND   old_items.feed_id = CAST (?2 AS INTEGER)
+//	AND   new_items.feed_id = CAST (?3 AS INTEGER)
+func (q *Queries) ListMatchingItemStates(ctx context.Context, arg ListMatchingItemStatesParams) ([]ListMatchingItemStatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchingItemStates, arg.UserID, arg.OldFeedID, arg.NewFeedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMatchingItemStatesRow{}
+	for rows.Next() {
+		var i ListMatchingItemStatesRow
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.Status,
+			&i.Starred,
+			&i.Tags,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentItems = `-- name: ListRecentItems :many
+SELECT items.id, items.feed_id, items.title, items.link, items.description, items.published_at, items.created_at, items.updated_at, items.hash, items.content, items.author, items.categories, items.source_updated_at, items.extracted_content, items.extracted_at FROM items
+JOIN subscriptions ON items.feed_id = subscriptions.feed_id
+WHERE subscriptions.user_id = CAST (?1 AS INTEGER)
+AND   (CAST (?2 AS BOOL) = 0 OR items.feed_id = ?3)
+ORDER BY items.published_at DESC
+LIMIT ?4
+`
+
+type ListRecentItemsParams struct {
+	UserID  int64
+	HasFeed bool
+	FeedID  int64
+	Limit   int64
+}
+
+// ListRecentItems
+//
+//	SELECT items.id, items.feed_id, items.title, items.link, items.description, items.published_at, items.created_at, items.updated_at, items.hash, items.content, items.author, items.categories, items.source_updated_at, items.extracted_content, items.extracted_at FROM items
+//	JOIN subscriptions ON items.feed_id = subscriptions.feed_id
+//	WHERE subscriptions.user_id = CAST (?1 AS INTEGER)
+//	AND   (CAST (?2 AS BOOL) = 0 OR items.feed_id = ?3)
+//	ORDER BY items.published_at DESC
+//	LIMIT ?4
+func (q *Queries) ListRecentItems(ctx context.Context, arg ListRecentItemsParams) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentItems,
+		arg.UserID,
+		arg.HasFeed,
+		arg.FeedID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Title,
+			&i.Link,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Hash,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.SourceUpdatedAt,
+			&i.ExtractedContent,
+			&i.ExtractedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markItemsAsRead = `-- name: MarkItemsAsRead :exec
+UPDATE item_states SET status = "read"
+WHERE item_states.status = "unread"
+AND   item_states.user_id = CAST (?1 AS INTEGER)
+AND   item_states.item_id IN (
+    SELECT items.id FROM items
+    JOIN subscriptions ON subscriptions.feed_id = items.feed_id
+    WHERE subscriptions.user_id = CAST (?1 AS INTEGER)
+    AND   (CAST (?2 AS BOOL)     = 0 OR items.feed_id = ?3)
+    AND   (CAST (?4 AS BOOL) = 0 OR subscriptions.category_id = CAST (?5 AS INTEGER))
+    AND   (CAST (?6 AS BOOL)  = 0 OR items.published_at <= ?7)
+)
+AND   (CAST (?8 AS BOOL) = 0 OR item_states.starred)
+`
+
+type MarkItemsAsReadParams struct {
+	UserID        int64
+	HasFeedID     bool
+	FeedID        int64
+	HasCategoryID bool
+	CategoryID    int64
+	HasOlderThan  bool
+	OlderThan     time.Time
+	StarredOnly   bool
+}
+
+// MarkItemsAsRead
+//
+//	UPDATE item_states SET status = "read"
+//	WHERE item_states.status = "unread"
+//	AND   item_states.user_id = CAST (?1 AS INTEGER)
+//	AND   item_states.item_id IN (
+//	    SELECT items.id FROM items
+//	    JOIN subscriptions ON subscriptions.feed_id = items.feed_id
+//	    WHERE subscriptions.user_id = CAST (?1 AS INTEGER)
+//	    AND   (CAST (?2 AS BOOL)     = 0 OR items.feed_id = ?3)
+//	    AND   (CAST (?4 AS BOOL) = 0 OR subscriptions.category_id = CAST (?5 AS INTEGER))
+//	    AND   (CAST (?6 AS BOOL)  = 0 OR items.published_at <= ?7)
+//	)
+//	AND   (CAST (?8 AS BOOL) = 0 OR item_states.starred)
+func (q *Queries) MarkItemsAsRead(ctx context.Context, arg MarkItemsAsReadParams) error {
+	_, err := q.db.ExecContext(ctx, markItemsAsRead,
+		arg.UserID,
+		arg.HasFeedID,
+		arg.FeedID,
+		arg.HasCategoryID,
+		arg.CategoryID,
+		arg.HasOlderThan,
+		arg.OlderThan,
+		arg.StarredOnly,
+	)
 	return err
 }
 
 const updateItem = `-- name: UpdateItem :exec
-UPDATE items SET title = ?, link = ?, description = ?, published_at = ? WHERE id = ?
+UPDATE items
+SET title = ?1,
+    link = ?2,
+    description = ?3,
+    content = ?4,
+    author = ?5,
+    categories = ?6,
+    published_at = ?7,
+    source_updated_at = ?8,
+    extracted_content = CASE WHEN link = ?2 THEN extracted_content ELSE '' END,
+    extracted_at = CASE WHEN link = ?2 THEN extracted_at ELSE NULL END
+WHERE id = ?9
 `
 
 type UpdateItemParams struct {
-	Title       string
-	Link        string
-	Description string
-	PublishedAt time.Time
-	ID          int64
+	Title           string
+	Link            string
+	Description     string
+	Content         string
+	Author          string
+	Categories      string
+	PublishedAt     time.Time
+	SourceUpdatedAt sql.NullTime
+	ID              int64
 }
 
 // UpdateItem
 //
-//	UPDATE items SET title = ?, link = ?, description = ?, published_at = ? WHERE id = ?
+//	UPDATE items
+//	SET title = ?1,
+//	    link = ?2,
+//	    description = ?3,
+//	    content = ?4,
+//	    author = ?5,
+//	    categories = ?6,
+//	    published_at = ?7,
+//	    source_updated_at = ?8,
+//	    extracted_content = CASE WHEN link = ?2 THEN extracted_content ELSE '' END,
+//	    extracted_at = CASE WHEN link = ?2 THEN extracted_at ELSE NULL END
+//	WHERE id = ?9
 func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) error {
 	_, err := q.db.ExecContext(ctx, updateItem,
 		arg.Title,
 		arg.Link,
 		arg.Description,
+		arg.Content,
+		arg.Author,
+		arg.Categories,
 		arg.PublishedAt,
+		arg.SourceUpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateItemExtractedContent = `-- name: UpdateItemExtractedContent :exec
+UPDATE items SET extracted_content = ?, extracted_at = ? WHERE id = ?
+`
+
+type UpdateItemExtractedContentParams struct {
+	ExtractedContent string
+	ExtractedAt      sql.NullTime
+	ID               int64
+}
+
+// UpdateItemExtractedContent
+//
+//	UPDATE items SET extracted_content = ?, extracted_at = ? WHERE id = ?
+func (q *Queries) UpdateItemExtractedContent(ctx context.Context, arg UpdateItemExtractedContentParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemExtractedContent, arg.ExtractedContent, arg.ExtractedAt, arg.ID)
+	return err
+}
+
+const updateItemReadAt = `-- name: UpdateItemReadAt :exec
+UPDATE item_states SET read_at = CAST (?1 AS TEXT)
+WHERE item_id = ?2 AND user_id = CAST (?3 AS INTEGER)
+`
+
+type UpdateItemReadAtParams struct {
+	ReadAt string
+	ID     int64
+	UserID int64
+}
+
+// UpdateItemReadAt
+//
+//	UPDATE item_states SET read_at = CAST (?1 AS TEXT)
+//	WHERE item_id = ?2 AND user_id = CAST (?3 AS INTEGER)
+func (q *Queries) UpdateItemReadAt(ctx context.Context, arg UpdateItemReadAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemReadAt, arg.ReadAt, arg.ID, arg.UserID)
+	return err
+}
+
+const updateItemStarred = `-- name: UpdateItemStarred :exec
+UPDATE item_states SET starred = ?1
+WHERE item_id = ?2 AND user_id = CAST (?3 AS INTEGER)
+`
+
+type UpdateItemStarredParams struct {
+	Starred bool
+	ID      int64
+	UserID  int64
+}
+
+// UpdateItemStarred
+//
+//	UPDATE item_states SET starred = ?1
+//	WHERE item_id = ?2 AND user_id = CAST (?3 AS INTEGER)
+func (q *Queries) UpdateItemStarred(ctx context.Context, arg UpdateItemStarredParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemStarred, arg.Starred, arg.ID, arg.UserID)
+	return err
+}
+
+const updateItemState = `-- name: UpdateItemState :exec
+UPDATE item_states SET status = ?1, starred = ?2, tags = ?3
+WHERE item_id = ?4 AND user_id = CAST (?5 AS INTEGER)
+`
+
+type UpdateItemStateParams struct {
+	Status  Status
+	Starred bool
+	Tags    string
+	ID      int64
+	UserID  int64
+}
+
+// UpdateItemState
+//
+//	UPDATE item_states SET status = ?1, starred = ?2, tags = ?3
+//	WHERE item_id = ?4 AND user_id = CAST (?5 AS INTEGER)
+func (q *Queries) UpdateItemState(ctx context.Context, arg UpdateItemStateParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemState,
+		arg.Status,
+		arg.Starred,
+		arg.Tags,
 		arg.ID,
+		arg.UserID,
 	)
 	return err
 }
 
-const updateItemStatus = `-- name: UpdateItemStatus :one
-UPDATE items SET status = ? WHERE id = ? RETURNING id, feed_id, title, link, description, status, published_at, created_at, updated_at, hash
+const updateItemStatus = `-- name: UpdateItemStatus :exec
+UPDATE item_states SET status = ?1
+WHERE item_id = ?2 AND user_id = CAST (?3 AS INTEGER)
 `
 
 type UpdateItemStatusParams struct {
 	Status Status
 	ID     int64
+	UserID int64
 }
 
 // UpdateItemStatus
 //
-//	UPDATE items SET status = ? WHERE id = ? RETURNING id, feed_id, title, link, description, status, published_at, created_at, updated_at, hash
-func (q *Queries) UpdateItemStatus(ctx context.Context, arg UpdateItemStatusParams) (Item, error) {
-	row := q.db.QueryRowContext(ctx, updateItemStatus, arg.Status, arg.ID)
-	var i Item
-	err := row.Scan(
-		&i.ID,
-		&i.FeedID,
-		&i.Title,
-		&i.Link,
-		&i.Description,
-		&i.Status,
-		&i.PublishedAt,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Hash,
-	)
-	return i, err
+//	UPDATE item_states SET status = ?1
+//	WHERE item_id = ?2 AND user_id = CAST (?3 AS INTEGER)
+func (q *Queries) UpdateItemStatus(ctx context.Context, arg UpdateItemStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemStatus, arg.Status, arg.ID, arg.UserID)
+	return err
+}
+
+const updateItemsStatus = `-- name: UpdateItemsStatus :exec
+UPDATE item_states SET status = ?1
+WHERE user_id = CAST (?2 AS INTEGER) AND item_id IN (/*SLICE:ids*/?)
+`
+
+type UpdateItemsStatusParams struct {
+	Status Status
+	UserID int64
+	Ids    []int64
+}
+
+// UpdateItemsStatus
+//
+//	UPDATE item_states SET status = ?1
+//	WHERE user_id = CAST (?2 AS INTEGER) AND item_id IN (/*SLICE:ids*/?)
+func (q *Queries) UpdateItemsStatus(ctx context.Context, arg UpdateItemsStatusParams) error {
+	query := updateItemsStatus
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.Status)
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
 }
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	benchNumFeeds     = 200
+	benchItemsPerFeed = 500
+	benchPageSize     = 20
+)
+
+// offsetListItemsSQL is how item lists were paged before they used cursors,
+// kept to compare against ListItems.
+const offsetListItemsSQL = `
+SELECT user_items.*, user_feeds.* FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = ?
+AND   (? = 0 OR user_items.status = ?)
+ORDER BY user_items.published_at DESC
+LIMIT ? OFFSET ?`
+
+// seedBenchDB creates a database with 100k items across 200 feeds. Alice
+// subscribes to every feed and has read 9 in 10 items, and bob subscribes to
+// half of them. Items are published in pairs, so some share a published time.
+func seedBenchDB(b *testing.B) (*sql.DB, int64) {
+	b.Helper()
+
+	ctx := b.Context()
+	dsn := filepath.Join(b.TempDir(), "bench.db")
+
+	db, err := Init(ctx, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	alice, err := seedBenchItems(ctx, db)
+	db.Close()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// Open it again so that Init analyzes the seeded tables, like it would
+	// when the server restarts.
+	db, err = Init(ctx, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	return db, alice
+}
+
+func seedBenchItems(ctx context.Context, db *sql.DB) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := New(db).WithTx(tx)
+
+	alice, err := q.CreateUser(ctx, CreateUserParams{Username: "alice", PasswordHash: "x"})
+	if err != nil {
+		return 0, fmt.Errorf("creating user: %w", err)
+	}
+	bob, err := q.CreateUser(ctx, CreateUserParams{Username: "bob", PasswordHash: "x"})
+	if err != nil {
+		return 0, fmt.Errorf("creating user: %w", err)
+	}
+
+	oldest := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	numItems := 0
+
+	for f := range benchNumFeeds {
+		feed, err := q.CreateFeed(ctx, CreateFeedParams{
+			Title: fmt.Sprintf("Feed %d", f),
+			URL:   fmt.Sprintf("https://example.com/%d.xml", f),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("creating feed: %w", err)
+		}
+
+		for i := range benchItemsPerFeed {
+			// Interleave the feeds, like they would be when refreshed over time.
+			n := i*benchNumFeeds + f
+			if _, err := q.CreateItem(ctx, CreateItemParams{
+				FeedID:      feed.ID,
+				Title:       fmt.Sprintf("Item %d", n),
+				Link:        fmt.Sprintf("https://example.com/%d/%d", f, i),
+				Description: "A short description of the item.",
+				Categories:  "[]",
+				Hash:        fmt.Sprint(n),
+				PublishedAt: oldest.Add(time.Duration(n/2) * time.Minute),
+			}); err != nil {
+				return 0, fmt.Errorf("creating item: %w", err)
+			}
+			numItems++
+		}
+
+		users := []int64{alice.ID}
+		if f%2 == 0 {
+			users = append(users, bob.ID)
+		}
+		for _, userID := range users {
+			if err := q.CreateSubscription(ctx, CreateSubscriptionParams{UserID: userID, FeedID: feed.ID, Title: feed.Title}); err != nil {
+				return 0, fmt.Errorf("creating subscription: %w", err)
+			}
+			if err := q.CreateSubscriptionItemStates(ctx, CreateSubscriptionItemStatesParams{UserID: userID, FeedID: feed.ID}); err != nil {
+				return 0, fmt.Errorf("creating item states: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE item_states SET status = 'read' WHERE user_id = ? AND item_id % 10 != 0",
+		alice.ID,
+	); err != nil {
+		return 0, fmt.Errorf("marking items as read: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return alice.ID, nil
+}
+
+// BenchmarkListItems compares paging through alice's item lists with an
+// offset and with a cursor, at increasing depths on a seeded 100k-item
+// database. Seeding takes a while, so run it on its own:
+//
+//	go test ./database -run '^$' -bench ListItems
+func BenchmarkListItems(b *testing.B) {
+	db, alice := seedBenchDB(b)
+	q := New(db)
+
+	lists := []struct {
+		name   string
+		status Status
+		// depths are in pages.
+		depths []int
+	}{
+		{name: "history", depths: []int{0, 500, 2000, 4999}},
+		{name: "unread", status: StatusUnread, depths: []int{0, 50, 200, 499}},
+	}
+
+	for _, list := range lists {
+		hasStatus := list.status != ""
+
+		for _, depth := range list.depths {
+			offset := depth * benchPageSize
+
+			b.Run(fmt.Sprintf("%s/offset/depth=%d", list.name, depth), func(b *testing.B) {
+				for b.Loop() {
+					rows, err := db.QueryContext(b.Context(), offsetListItemsSQL, alice, hasStatus, list.status, benchPageSize, offset)
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					n := 0
+					for rows.Next() {
+						n++
+					}
+					rows.Close()
+					if err := rows.Err(); err != nil {
+						b.Fatal(err)
+					}
+					if n != benchPageSize {
+						b.Fatalf("listed %d items, want %d", n, benchPageSize)
+					}
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/cursor/depth=%d", list.name, depth), func(b *testing.B) {
+				cursor := FirstPageCursor(ItemOrderNewest)
+				if depth > 0 {
+					cursor = benchCursorAt(b, db, alice, list.status, offset-1)
+				}
+
+				params := ListItemsParams{
+					UserID:            alice,
+					HasStatus:         hasStatus,
+					Status:            list.status,
+					CursorPublishedAt: cursor.Time,
+					CursorID:          cursor.ID,
+					Limit:             benchPageSize,
+				}
+
+				for b.Loop() {
+					rows, err := q.ListItems(b.Context(), params)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(rows) != benchPageSize {
+						b.Fatalf("listed %d items, want %d", len(rows), benchPageSize)
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchCursorAt returns the cursor of the page after the item at offset in
+// alice's list.
+func benchCursorAt(b *testing.B, db *sql.DB, alice int64, status Status, offset int) ItemCursor {
+	b.Helper()
+
+	var id int64
+	if err := db.QueryRowContext(
+		b.Context(),
+		`SELECT id FROM user_items
+		WHERE user_id = ? AND (? = '' OR status = ?)
+		ORDER BY published_at DESC, id DESC
+		LIMIT 1 OFFSET ?`,
+		alice, status, status, offset,
+	).Scan(&id); err != nil {
+		b.Fatal(err)
+	}
+
+	row, err := New(db).GetItem(b.Context(), GetItemParams{ID: id, UserID: alice})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return row.UserItem.Cursor(ItemOrderNewest)
+}
@@ -10,7 +10,25 @@ func (i Feed) LogValue() slog.Attr {
 	)
 }
 
+func (i UserFeed) LogValue() slog.Attr {
+	return slog.GroupAttrs("feed",
+		slog.Int64("id", i.ID),
+		slog.String("title", i.Title),
+		slog.String("url", i.URL),
+	)
+}
+
 func (i Item) LogValue() slog.Attr {
+	return slog.GroupAttrs("feed",
+		slog.Int64("id", i.ID),
+		slog.Int64("feed_id", i.FeedID),
+		slog.String("title", i.Title),
+		slog.String("url", i.Link),
+		slog.String("hash", i.Hash),
+	)
+}
+
+func (i UserItem) LogValue() slog.Attr {
 	return slog.GroupAttrs("feed",
 		slog.Int64("id", i.ID),
 		slog.Int64("feed_id", i.FeedID),
@@ -20,3 +38,10 @@ func (i Item) LogValue() slog.Attr {
 		slog.String("hash", i.Hash),
 	)
 }
+
+func (u User) LogValue() slog.Attr {
+	return slog.GroupAttrs("user",
+		slog.Int64("id", u.ID),
+		slog.String("username", u.Username),
+	)
+}
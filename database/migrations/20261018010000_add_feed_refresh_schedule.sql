@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feeds ADD COLUMN next_refresh_at TIMESTAMP;
+ALTER TABLE feeds ADD COLUMN refresh_interval_minutes INTEGER;
+ALTER TABLE feeds ADD COLUMN ttl_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN idle_refreshes INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS feeds_next_refresh_at_ix ON feeds(next_refresh_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS feeds_next_refresh_at_ix;
+
+ALTER TABLE feeds DROP COLUMN idle_refreshes;
+ALTER TABLE feeds DROP COLUMN ttl_seconds;
+ALTER TABLE feeds DROP COLUMN refresh_interval_minutes;
+ALTER TABLE feeds DROP COLUMN next_refresh_at;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE IF NOT EXISTS items_fts USING fts5(
+  title,
+  description,
+  content = 'items',
+  content_rowid = 'id'
+);
+
+INSERT INTO items_fts(items_fts) VALUES ('rebuild');
+
+CREATE TRIGGER IF NOT EXISTS items_fts_insert
+AFTER INSERT ON items
+BEGIN
+  INSERT INTO items_fts(rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS items_fts_delete
+AFTER DELETE ON items
+BEGIN
+  INSERT INTO items_fts(items_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS items_fts_update
+AFTER UPDATE OF title, description ON items
+BEGIN
+  INSERT INTO items_fts(items_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+  INSERT INTO items_fts(rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS items_fts_update;
+DROP TRIGGER IF EXISTS items_fts_delete;
+DROP TRIGGER IF EXISTS items_fts_insert;
+DROP TABLE IF EXISTS items_fts;
+-- +goose StatementEnd
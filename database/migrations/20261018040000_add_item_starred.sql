@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE items ADD COLUMN starred BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS items_starred_ix ON items(starred) WHERE starred;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS items_starred_ix;
+
+ALTER TABLE items DROP COLUMN starred;
+-- +goose StatementEnd
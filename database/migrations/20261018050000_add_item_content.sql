@@ -0,0 +1,83 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE items ADD COLUMN content TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN categories TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE items ADD COLUMN source_updated_at TIMESTAMP;
+
+-- reindex with content
+DROP TRIGGER IF EXISTS items_fts_update;
+DROP TRIGGER IF EXISTS items_fts_delete;
+DROP TRIGGER IF EXISTS items_fts_insert;
+DROP TABLE IF EXISTS items_fts;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS items_fts USING fts5(
+  title,
+  description,
+  content,
+  content = 'items',
+  content_rowid = 'id'
+);
+
+INSERT INTO items_fts(items_fts) VALUES ('rebuild');
+
+CREATE TRIGGER IF NOT EXISTS items_fts_insert
+AFTER INSERT ON items
+BEGIN
+  INSERT INTO items_fts(rowid, title, description, content) VALUES (NEW.id, NEW.title, NEW.description, NEW.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS items_fts_delete
+AFTER DELETE ON items
+BEGIN
+  INSERT INTO items_fts(items_fts, rowid, title, description, content) VALUES ('delete', OLD.id, OLD.title, OLD.description, OLD.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS items_fts_update
+AFTER UPDATE OF title, description, content ON items
+BEGIN
+  INSERT INTO items_fts(items_fts, rowid, title, description, content) VALUES ('delete', OLD.id, OLD.title, OLD.description, OLD.content);
+  INSERT INTO items_fts(rowid, title, description, content) VALUES (NEW.id, NEW.title, NEW.description, NEW.content);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS items_fts_update;
+DROP TRIGGER IF EXISTS items_fts_delete;
+DROP TRIGGER IF EXISTS items_fts_insert;
+DROP TABLE IF EXISTS items_fts;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS items_fts USING fts5(
+  title,
+  description,
+  content = 'items',
+  content_rowid = 'id'
+);
+
+INSERT INTO items_fts(items_fts) VALUES ('rebuild');
+
+CREATE TRIGGER IF NOT EXISTS items_fts_insert
+AFTER INSERT ON items
+BEGIN
+  INSERT INTO items_fts(rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS items_fts_delete
+AFTER DELETE ON items
+BEGIN
+  INSERT INTO items_fts(items_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS items_fts_update
+AFTER UPDATE OF title, description ON items
+BEGIN
+  INSERT INTO items_fts(items_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+  INSERT INTO items_fts(rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+END;
+
+ALTER TABLE items DROP COLUMN source_updated_at;
+ALTER TABLE items DROP COLUMN categories;
+ALTER TABLE items DROP COLUMN author;
+ALTER TABLE items DROP COLUMN content;
+-- +goose StatementEnd
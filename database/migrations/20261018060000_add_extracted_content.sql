@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feeds ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE items ADD COLUMN extracted_content TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN extracted_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE items DROP COLUMN extracted_at;
+ALTER TABLE items DROP COLUMN extracted_content;
+
+ALTER TABLE feeds DROP COLUMN fetch_full_content;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS rules (
+  id INTEGER PRIMARY KEY,
+  -- NULL for rules that apply to every feed.
+  feed_id INTEGER REFERENCES feeds(id) ON DELETE CASCADE,
+  field TEXT NOT NULL,
+  match_type TEXT NOT NULL,
+  pattern TEXT NOT NULL,
+  action TEXT NOT NULL,
+  tag TEXT NOT NULL DEFAULT '',
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS rules_feed_id_ix ON rules(feed_id);
+
+ALTER TABLE items ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+
+-- Replaces the YouTube Shorts skip that used to be hard-coded.
+INSERT INTO rules (field, match_type, pattern, action)
+VALUES ('link', 'regex', '^https://www\.youtube\.com/shorts/', 'drop');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE items DROP COLUMN tags;
+
+DROP INDEX IF EXISTS rules_feed_id_ix;
+
+DROP TABLE IF EXISTS rules;
+-- +goose StatementEnd
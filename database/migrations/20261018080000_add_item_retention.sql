@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL uses the global retention policy, 0 keeps items forever.
+ALTER TABLE feeds ADD COLUMN retention_read_days INTEGER;
+ALTER TABLE feeds ADD COLUMN retention_max_items INTEGER;
+
+-- Hashes of items deleted by the retention policy, so they aren't added back
+-- as new items while they are still in the feed.
+CREATE TABLE IF NOT EXISTS item_tombstones (
+  feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+  hash TEXT NOT NULL,
+  deleted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (feed_id, hash)
+);
+
+CREATE INDEX IF NOT EXISTS items_feed_id_published_at_ix ON items(feed_id, published_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS items_feed_id_published_at_ix;
+
+DROP TABLE IF EXISTS item_tombstones;
+
+ALTER TABLE feeds DROP COLUMN retention_max_items;
+ALTER TABLE feeds DROP COLUMN retention_read_days;
+-- +goose StatementEnd
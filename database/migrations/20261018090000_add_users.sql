@@ -0,0 +1,248 @@
+-- +goose NO TRANSACTION
+
+-- Feeds, categories and rules are rebuilt so their unique constraints include
+-- the owner. Foreign keys are turned off while the old tables are dropped, or
+-- dropping feeds would delete every item. Rows that existed before accounts
+-- have no owner until the first account is created, which claims them.
+
+-- +goose Up
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY,
+  username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+  token_hash TEXT PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_ix ON sessions(user_id);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id INTEGER PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  -- The Fever API authenticates with md5("username:token"), which can't be
+  -- derived from token_hash, so it is stored when the token is created.
+  fever_key TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS api_tokens_user_id_ix ON api_tokens(user_id);
+
+CREATE TABLE categories_temp (
+  id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  UNIQUE (user_id, title)
+);
+
+INSERT INTO categories_temp (id, title, created_at)
+SELECT id, title, created_at FROM categories;
+
+DROP TABLE categories;
+ALTER TABLE categories_temp RENAME TO categories;
+
+CREATE TABLE feeds_temp (
+  id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  url TEXT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP,
+  last_refreshed_at TIMESTAMP,
+  image TEXT,
+  category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+  etag TEXT,
+  last_modified TEXT,
+  last_content_length INTEGER NOT NULL DEFAULT 0,
+  bytes_saved INTEGER NOT NULL DEFAULT 0,
+  next_refresh_at TIMESTAMP,
+  refresh_interval_minutes INTEGER,
+  ttl_seconds INTEGER NOT NULL DEFAULT 0,
+  idle_refreshes INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  last_error_at TIMESTAMP,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  last_http_status INTEGER,
+  fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE,
+  retention_read_days INTEGER,
+  retention_max_items INTEGER,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  UNIQUE (user_id, url)
+);
+
+INSERT INTO feeds_temp (
+  id, title, url, created_at, updated_at, last_refreshed_at, image, category_id,
+  etag, last_modified, last_content_length, bytes_saved, next_refresh_at,
+  refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error,
+  last_error_at, consecutive_failures, last_http_status, fetch_full_content,
+  retention_read_days, retention_max_items
+)
+SELECT
+  id, title, url, created_at, updated_at, last_refreshed_at, image, category_id,
+  etag, last_modified, last_content_length, bytes_saved, next_refresh_at,
+  refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error,
+  last_error_at, consecutive_failures, last_http_status, fetch_full_content,
+  retention_read_days, retention_max_items
+FROM feeds;
+
+DROP TABLE feeds;
+ALTER TABLE feeds_temp RENAME TO feeds;
+
+CREATE INDEX IF NOT EXISTS feeds_category_id_ix ON feeds(category_id);
+CREATE INDEX IF NOT EXISTS feeds_next_refresh_at_ix ON feeds(next_refresh_at);
+CREATE INDEX IF NOT EXISTS feeds_user_id_ix ON feeds(user_id);
+
+CREATE TRIGGER IF NOT EXISTS feeds_set_updated_at
+AFTER UPDATE ON feeds
+FOR EACH ROW
+BEGIN
+  UPDATE feeds
+  SET updated_at = CURRENT_TIMESTAMP
+  WHERE id = NEW.id;
+END;
+
+CREATE TABLE rules_temp (
+  id INTEGER PRIMARY KEY,
+  -- NULL for rules that apply to every feed of the user.
+  feed_id INTEGER REFERENCES feeds(id) ON DELETE CASCADE,
+  field TEXT NOT NULL,
+  match_type TEXT NOT NULL,
+  pattern TEXT NOT NULL,
+  action TEXT NOT NULL,
+  tag TEXT NOT NULL DEFAULT '',
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO rules_temp (id, feed_id, field, match_type, pattern, action, tag, created_at)
+SELECT id, feed_id, field, match_type, pattern, action, tag, created_at FROM rules;
+
+DROP TABLE rules;
+ALTER TABLE rules_temp RENAME TO rules;
+
+CREATE INDEX IF NOT EXISTS rules_feed_id_ix ON rules(feed_id);
+CREATE INDEX IF NOT EXISTS rules_user_id_ix ON rules(user_id);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE rules_temp (
+  id INTEGER PRIMARY KEY,
+  -- NULL for rules that apply to every feed.
+  feed_id INTEGER REFERENCES feeds(id) ON DELETE CASCADE,
+  field TEXT NOT NULL,
+  match_type TEXT NOT NULL,
+  pattern TEXT NOT NULL,
+  action TEXT NOT NULL,
+  tag TEXT NOT NULL DEFAULT '',
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO rules_temp (id, feed_id, field, match_type, pattern, action, tag, created_at)
+SELECT id, feed_id, field, match_type, pattern, action, tag, created_at FROM rules;
+
+DROP TABLE rules;
+ALTER TABLE rules_temp RENAME TO rules;
+
+CREATE INDEX IF NOT EXISTS rules_feed_id_ix ON rules(feed_id);
+
+CREATE TABLE feeds_temp (
+  id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  url TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP,
+  last_refreshed_at TIMESTAMP,
+  image TEXT,
+  category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+  etag TEXT,
+  last_modified TEXT,
+  last_content_length INTEGER NOT NULL DEFAULT 0,
+  bytes_saved INTEGER NOT NULL DEFAULT 0,
+  next_refresh_at TIMESTAMP,
+  refresh_interval_minutes INTEGER,
+  ttl_seconds INTEGER NOT NULL DEFAULT 0,
+  idle_refreshes INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  last_error_at TIMESTAMP,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  last_http_status INTEGER,
+  fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE,
+  retention_read_days INTEGER,
+  retention_max_items INTEGER
+);
+
+-- Only one copy of each URL can be kept.
+INSERT OR IGNORE INTO feeds_temp
+SELECT
+  id, title, url, created_at, updated_at, last_refreshed_at, image, category_id,
+  etag, last_modified, last_content_length, bytes_saved, next_refresh_at,
+  refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error,
+  last_error_at, consecutive_failures, last_http_status, fetch_full_content,
+  retention_read_days, retention_max_items
+FROM feeds
+ORDER BY id;
+
+DELETE FROM items WHERE feed_id NOT IN (SELECT id FROM feeds_temp);
+DELETE FROM item_tombstones WHERE feed_id NOT IN (SELECT id FROM feeds_temp);
+DELETE FROM rules WHERE feed_id NOT IN (SELECT id FROM feeds_temp);
+
+DROP TABLE feeds;
+ALTER TABLE feeds_temp RENAME TO feeds;
+
+CREATE INDEX IF NOT EXISTS feeds_category_id_ix ON feeds(category_id);
+CREATE INDEX IF NOT EXISTS feeds_next_refresh_at_ix ON feeds(next_refresh_at);
+
+CREATE TRIGGER IF NOT EXISTS feeds_set_updated_at
+AFTER UPDATE ON feeds
+FOR EACH ROW
+BEGIN
+  UPDATE feeds
+  SET updated_at = CURRENT_TIMESTAMP
+  WHERE id = NEW.id;
+END;
+
+CREATE TABLE categories_temp (
+  id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+INSERT OR IGNORE INTO categories_temp
+SELECT id, title, created_at FROM categories
+ORDER BY id;
+
+UPDATE feeds SET category_id = NULL WHERE category_id NOT IN (SELECT id FROM categories_temp);
+
+DROP TABLE categories;
+ALTER TABLE categories_temp RENAME TO categories;
+
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
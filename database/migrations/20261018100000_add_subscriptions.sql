@@ -0,0 +1,330 @@
+-- +goose NO TRANSACTION
+
+-- Feeds and items are shared, so a URL is stored and fetched once however many
+-- users follow it. Subscriptions hold each user's title and category for a
+-- feed, and item states hold each user's read status, star and tags for an
+-- item. A user only sees the items they have a state for.
+--
+-- Feeds that several users had added separately are merged into the oldest
+-- one. Their items are matched up by hash so every user keeps their read
+-- status.
+
+-- +goose Up
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+  id INTEGER PRIMARY KEY,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+  title TEXT NOT NULL,
+  category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  UNIQUE (user_id, feed_id)
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_feed_id_ix ON subscriptions(feed_id);
+CREATE INDEX IF NOT EXISTS subscriptions_category_id_ix ON subscriptions(category_id);
+
+CREATE TABLE IF NOT EXISTS item_states (
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  status TEXT CHECK( status IN ('read', 'unread') ) NOT NULL DEFAULT 'unread',
+  starred BOOLEAN NOT NULL DEFAULT FALSE,
+  tags TEXT NOT NULL DEFAULT '[]',
+  UNIQUE (user_id, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS item_states_item_id_ix ON item_states(item_id);
+CREATE INDEX IF NOT EXISTS item_states_user_id_status_ix ON item_states(user_id, status);
+CREATE INDEX IF NOT EXISTS item_states_starred_ix ON item_states(user_id) WHERE starred;
+
+CREATE TEMP TABLE feed_merges AS
+SELECT feeds.id AS old_id, (SELECT MIN(oldest.id) FROM feeds AS oldest WHERE oldest.url = feeds.url) AS new_id
+FROM feeds;
+
+CREATE TEMP TABLE item_merges AS
+SELECT items.id AS old_id, COALESCE(
+  (
+    SELECT MIN(kept.id) FROM items AS kept
+    WHERE kept.feed_id = feed_merges.new_id AND kept.hash = items.hash
+  ),
+  items.id
+) AS new_id
+FROM items
+JOIN feed_merges ON feed_merges.old_id = items.feed_id;
+
+INSERT OR IGNORE INTO subscriptions (user_id, feed_id, title, category_id, created_at)
+SELECT feeds.user_id, feed_merges.new_id, feeds.title, feeds.category_id, feeds.created_at
+FROM feeds
+JOIN feed_merges ON feed_merges.old_id = feeds.id
+ORDER BY feeds.id;
+
+INSERT OR IGNORE INTO item_states (user_id, item_id, status, starred, tags)
+SELECT feeds.user_id, item_merges.new_id, items.status, items.starred, items.tags
+FROM items
+JOIN item_merges ON item_merges.old_id = items.id
+JOIN feeds ON feeds.id = items.feed_id;
+
+DELETE FROM items WHERE id IN (SELECT old_id FROM item_merges WHERE old_id != new_id);
+
+UPDATE items
+SET feed_id = (SELECT new_id FROM feed_merges WHERE old_id = items.feed_id)
+WHERE feed_id IN (SELECT old_id FROM feed_merges WHERE old_id != new_id);
+
+INSERT OR IGNORE INTO item_tombstones (feed_id, hash, deleted_at)
+SELECT feed_merges.new_id, item_tombstones.hash, item_tombstones.deleted_at
+FROM item_tombstones
+JOIN feed_merges ON feed_merges.old_id = item_tombstones.feed_id
+WHERE feed_merges.old_id != feed_merges.new_id;
+
+DELETE FROM item_tombstones WHERE feed_id IN (SELECT old_id FROM feed_merges WHERE old_id != new_id);
+
+UPDATE rules
+SET feed_id = (SELECT new_id FROM feed_merges WHERE old_id = rules.feed_id)
+WHERE feed_id IN (SELECT old_id FROM feed_merges WHERE old_id != new_id);
+
+CREATE TABLE feeds_temp (
+  id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  url TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP,
+  last_refreshed_at TIMESTAMP,
+  image TEXT,
+  etag TEXT,
+  last_modified TEXT,
+  last_content_length INTEGER NOT NULL DEFAULT 0,
+  bytes_saved INTEGER NOT NULL DEFAULT 0,
+  next_refresh_at TIMESTAMP,
+  refresh_interval_minutes INTEGER,
+  ttl_seconds INTEGER NOT NULL DEFAULT 0,
+  idle_refreshes INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  last_error_at TIMESTAMP,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  last_http_status INTEGER,
+  fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE,
+  retention_read_days INTEGER,
+  retention_max_items INTEGER
+);
+
+INSERT INTO feeds_temp
+SELECT
+  id, title, url, created_at, updated_at, last_refreshed_at, image, etag,
+  last_modified, last_content_length, bytes_saved, next_refresh_at,
+  refresh_interval_minutes, ttl_seconds, idle_refreshes, last_error,
+  last_error_at, consecutive_failures, last_http_status, fetch_full_content,
+  retention_read_days, retention_max_items
+FROM feeds
+WHERE id IN (SELECT new_id FROM feed_merges);
+
+DROP TABLE feeds;
+ALTER TABLE feeds_temp RENAME TO feeds;
+
+CREATE INDEX IF NOT EXISTS feeds_next_refresh_at_ix ON feeds(next_refresh_at);
+
+CREATE TRIGGER IF NOT EXISTS feeds_set_updated_at
+AFTER UPDATE ON feeds
+FOR EACH ROW
+BEGIN
+  UPDATE feeds
+  SET updated_at = CURRENT_TIMESTAMP
+  WHERE id = NEW.id;
+END;
+
+DROP INDEX IF EXISTS items_starred_ix;
+
+ALTER TABLE items DROP COLUMN status;
+ALTER TABLE items DROP COLUMN starred;
+ALTER TABLE items DROP COLUMN tags;
+
+DROP TABLE feed_merges;
+DROP TABLE item_merges;
+
+-- Unsubscribing drops the user's item states and rules for the feed, and the
+-- feed itself once nobody follows it.
+CREATE TRIGGER IF NOT EXISTS subscriptions_delete
+AFTER DELETE ON subscriptions
+FOR EACH ROW
+BEGIN
+  DELETE FROM item_states
+  WHERE user_id IS OLD.user_id
+  AND   item_id IN (SELECT id FROM items WHERE feed_id = OLD.feed_id);
+
+  DELETE FROM rules WHERE user_id IS OLD.user_id AND feed_id = OLD.feed_id;
+
+  DELETE FROM feeds
+  WHERE id = OLD.feed_id
+  AND   NOT EXISTS (SELECT 1 FROM subscriptions WHERE feed_id = OLD.feed_id);
+END;
+
+-- A feed as one of its subscribers sees it.
+CREATE VIEW IF NOT EXISTS user_feeds AS
+SELECT
+  feeds.id,
+  subscriptions.title,
+  feeds.url,
+  subscriptions.created_at,
+  feeds.updated_at,
+  feeds.last_refreshed_at,
+  feeds.image,
+  subscriptions.category_id,
+  feeds.etag,
+  feeds.last_modified,
+  feeds.last_content_length,
+  feeds.bytes_saved,
+  feeds.next_refresh_at,
+  feeds.refresh_interval_minutes,
+  feeds.ttl_seconds,
+  feeds.idle_refreshes,
+  feeds.last_error,
+  feeds.last_error_at,
+  feeds.consecutive_failures,
+  feeds.last_http_status,
+  feeds.fetch_full_content,
+  feeds.retention_read_days,
+  feeds.retention_max_items,
+  subscriptions.user_id
+FROM feeds
+JOIN subscriptions ON subscriptions.feed_id = feeds.id;
+
+-- An item with one user's state.
+CREATE VIEW IF NOT EXISTS user_items AS
+SELECT
+  items.id,
+  items.feed_id,
+  items.title,
+  items.link,
+  items.description,
+  item_states.status,
+  items.published_at,
+  items.created_at,
+  items.updated_at,
+  items.hash,
+  item_states.starred,
+  items.content,
+  items.author,
+  items.categories,
+  items.source_updated_at,
+  items.extracted_content,
+  items.extracted_at,
+  item_states.tags,
+  item_states.user_id
+FROM items
+JOIN item_states ON item_states.item_id = items.id;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+DROP VIEW IF EXISTS user_items;
+DROP VIEW IF EXISTS user_feeds;
+DROP TRIGGER IF EXISTS subscriptions_delete;
+
+-- Only the first subscriber of each feed keeps it.
+CREATE TEMP TABLE feed_owners AS
+SELECT subscriptions.feed_id, subscriptions.user_id, subscriptions.title, subscriptions.category_id
+FROM subscriptions
+WHERE subscriptions.id = (SELECT MIN(first.id) FROM subscriptions AS first WHERE first.feed_id = subscriptions.feed_id);
+
+ALTER TABLE items ADD COLUMN status TEXT CHECK( status IN ('read', 'unread') ) NOT NULL DEFAULT 'unread';
+ALTER TABLE items ADD COLUMN starred BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE items ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+
+UPDATE items
+SET status = COALESCE((
+      SELECT item_states.status FROM item_states
+      JOIN feed_owners ON feed_owners.feed_id = items.feed_id
+      WHERE item_states.item_id = items.id AND item_states.user_id IS feed_owners.user_id
+    ), status),
+    starred = COALESCE((
+      SELECT item_states.starred FROM item_states
+      JOIN feed_owners ON feed_owners.feed_id = items.feed_id
+      WHERE item_states.item_id = items.id AND item_states.user_id IS feed_owners.user_id
+    ), starred),
+    tags = COALESCE((
+      SELECT item_states.tags FROM item_states
+      JOIN feed_owners ON feed_owners.feed_id = items.feed_id
+      WHERE item_states.item_id = items.id AND item_states.user_id IS feed_owners.user_id
+    ), tags);
+
+CREATE INDEX IF NOT EXISTS items_starred_ix ON items(starred) WHERE starred;
+
+DELETE FROM rules
+WHERE feed_id IS NOT NULL
+AND   user_id IS NOT (SELECT user_id FROM feed_owners WHERE feed_owners.feed_id = rules.feed_id);
+
+CREATE TABLE feeds_temp (
+  id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  url TEXT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP,
+  last_refreshed_at TIMESTAMP,
+  image TEXT,
+  category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+  etag TEXT,
+  last_modified TEXT,
+  last_content_length INTEGER NOT NULL DEFAULT 0,
+  bytes_saved INTEGER NOT NULL DEFAULT 0,
+  next_refresh_at TIMESTAMP,
+  refresh_interval_minutes INTEGER,
+  ttl_seconds INTEGER NOT NULL DEFAULT 0,
+  idle_refreshes INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  last_error_at TIMESTAMP,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  last_http_status INTEGER,
+  fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE,
+  retention_read_days INTEGER,
+  retention_max_items INTEGER,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  UNIQUE (user_id, url)
+);
+
+INSERT INTO feeds_temp
+SELECT
+  feeds.id, COALESCE(feed_owners.title, feeds.title), feeds.url, feeds.created_at,
+  feeds.updated_at, feeds.last_refreshed_at, feeds.image, feed_owners.category_id,
+  feeds.etag, feeds.last_modified, feeds.last_content_length, feeds.bytes_saved,
+  feeds.next_refresh_at, feeds.refresh_interval_minutes, feeds.ttl_seconds,
+  feeds.idle_refreshes, feeds.last_error, feeds.last_error_at,
+  feeds.consecutive_failures, feeds.last_http_status, feeds.fetch_full_content,
+  feeds.retention_read_days, feeds.retention_max_items, feed_owners.user_id
+FROM feeds
+LEFT JOIN feed_owners ON feed_owners.feed_id = feeds.id;
+
+DROP TABLE feeds;
+ALTER TABLE feeds_temp RENAME TO feeds;
+
+CREATE INDEX IF NOT EXISTS feeds_category_id_ix ON feeds(category_id);
+CREATE INDEX IF NOT EXISTS feeds_next_refresh_at_ix ON feeds(next_refresh_at);
+CREATE INDEX IF NOT EXISTS feeds_user_id_ix ON feeds(user_id);
+
+CREATE TRIGGER IF NOT EXISTS feeds_set_updated_at
+AFTER UPDATE ON feeds
+FOR EACH ROW
+BEGIN
+  UPDATE feeds
+  SET updated_at = CURRENT_TIMESTAMP
+  WHERE id = NEW.id;
+END;
+
+DROP TABLE feed_owners;
+DROP TABLE IF EXISTS item_states;
+DROP TABLE IF EXISTS subscriptions;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Item lists page with a (published_at, id) cursor, newest first.
+DROP INDEX IF EXISTS items_published_at_ix;
+CREATE INDEX IF NOT EXISTS items_published_at_id_ix ON items(published_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS items_published_at_id_ix;
+CREATE INDEX IF NOT EXISTS items_published_at_ix ON items(published_at);
+-- +goose StatementEnd
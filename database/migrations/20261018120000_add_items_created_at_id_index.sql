@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Item lists can be sorted by when items were fetched.
+CREATE INDEX IF NOT EXISTS items_created_at_id_ix ON items(created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS items_created_at_id_ix;
+-- +goose StatementEnd
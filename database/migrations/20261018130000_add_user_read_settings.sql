@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN mark_read_on_open BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN mark_read_on_scroll BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN mark_read_on_scroll;
+ALTER TABLE users DROP COLUMN mark_read_on_open;
+-- +goose StatementEnd
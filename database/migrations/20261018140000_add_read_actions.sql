@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS read_actions (
+  id INTEGER PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS read_action_items (
+  action_id INTEGER NOT NULL REFERENCES read_actions(id) ON DELETE CASCADE,
+  item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  PRIMARY KEY (action_id, item_id)
+) WITHOUT ROWID;
+
+CREATE INDEX IF NOT EXISTS read_action_items_item_id_ix ON read_action_items(item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS read_action_items;
+DROP TABLE IF EXISTS read_actions;
+-- +goose StatementEnd
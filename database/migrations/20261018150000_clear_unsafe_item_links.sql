@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Links are rendered as hrefs, so only http and https links are kept.
+UPDATE items
+SET link = ''
+WHERE lower(link) NOT LIKE 'http://%' AND lower(link) NOT LIKE 'https://%';
+-- +goose StatementEnd
+
+-- +goose Down
+-- Cleared links can't be restored.
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE item_states ADD COLUMN read_at TIMESTAMP;
+
+-- When existing items were read isn't known, so they are counted as read now.
+-- Retention keeps them for a full window rather than deleting them early.
+UPDATE item_states SET read_at = CURRENT_TIMESTAMP WHERE status = 'read';
+
+CREATE TRIGGER IF NOT EXISTS item_states_set_read_at_on_insert
+AFTER INSERT ON item_states
+FOR EACH ROW WHEN NEW.status = 'read'
+BEGIN
+  UPDATE item_states
+  SET read_at = CURRENT_TIMESTAMP
+  WHERE rowid = NEW.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_states_set_read_at
+AFTER UPDATE OF status ON item_states
+FOR EACH ROW WHEN NEW.status IS NOT OLD.status
+BEGIN
+  UPDATE item_states
+  SET read_at = CASE NEW.status WHEN 'read' THEN CURRENT_TIMESTAMP ELSE NULL END
+  WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS item_states_set_read_at;
+DROP TRIGGER IF EXISTS item_states_set_read_at_on_insert;
+ALTER TABLE item_states DROP COLUMN read_at;
+-- +goose StatementEnd
@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feeds ADD COLUMN site_url TEXT;
+
+-- The site URL is read from the feed, so clear the cache headers to fetch it
+-- in full on the next refresh rather than getting a 304.
+UPDATE feeds SET etag = NULL, last_modified = NULL;
+
+DROP VIEW IF EXISTS user_feeds;
+
+CREATE VIEW IF NOT EXISTS user_feeds AS
+SELECT
+  feeds.id,
+  subscriptions.title,
+  feeds.url,
+  subscriptions.created_at,
+  feeds.updated_at,
+  feeds.last_refreshed_at,
+  feeds.image,
+  subscriptions.category_id,
+  feeds.etag,
+  feeds.last_modified,
+  feeds.last_content_length,
+  feeds.bytes_saved,
+  feeds.next_refresh_at,
+  feeds.refresh_interval_minutes,
+  feeds.ttl_seconds,
+  feeds.idle_refreshes,
+  feeds.last_error,
+  feeds.last_error_at,
+  feeds.consecutive_failures,
+  feeds.last_http_status,
+  feeds.fetch_full_content,
+  feeds.retention_read_days,
+  feeds.retention_max_items,
+  subscriptions.user_id,
+  feeds.site_url
+FROM feeds
+JOIN subscriptions ON subscriptions.feed_id = feeds.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS user_feeds;
+
+CREATE VIEW IF NOT EXISTS user_feeds AS
+SELECT
+  feeds.id,
+  subscriptions.title,
+  feeds.url,
+  subscriptions.created_at,
+  feeds.updated_at,
+  feeds.last_refreshed_at,
+  feeds.image,
+  subscriptions.category_id,
+  feeds.etag,
+  feeds.last_modified,
+  feeds.last_content_length,
+  feeds.bytes_saved,
+  feeds.next_refresh_at,
+  feeds.refresh_interval_minutes,
+  feeds.ttl_seconds,
+  feeds.idle_refreshes,
+  feeds.last_error,
+  feeds.last_error_at,
+  feeds.consecutive_failures,
+  feeds.last_http_status,
+  feeds.fetch_full_content,
+  feeds.retention_read_days,
+  feeds.retention_max_items,
+  subscriptions.user_id
+FROM feeds
+JOIN subscriptions ON subscriptions.feed_id = feeds.id;
+
+ALTER TABLE feeds DROP COLUMN site_url;
+-- +goose StatementEnd
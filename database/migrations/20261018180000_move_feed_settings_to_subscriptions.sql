@@ -0,0 +1,105 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Refresh, full-content and retention settings are per subscriber, so that one
+-- subscriber can't change them for everyone. Feeds with several subscribers
+-- follow whichever of their settings asks for the most.
+ALTER TABLE subscriptions ADD COLUMN refresh_interval_minutes INTEGER;
+ALTER TABLE subscriptions ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+-- NULL uses the global retention policy, 0 keeps items forever.
+ALTER TABLE subscriptions ADD COLUMN retention_read_days INTEGER;
+ALTER TABLE subscriptions ADD COLUMN retention_max_items INTEGER;
+
+UPDATE subscriptions
+SET refresh_interval_minutes = (SELECT refresh_interval_minutes FROM feeds WHERE id = subscriptions.feed_id),
+    fetch_full_content = (SELECT fetch_full_content FROM feeds WHERE id = subscriptions.feed_id),
+    retention_read_days = (SELECT retention_read_days FROM feeds WHERE id = subscriptions.feed_id),
+    retention_max_items = (SELECT retention_max_items FROM feeds WHERE id = subscriptions.feed_id);
+
+DROP VIEW IF EXISTS user_feeds;
+
+ALTER TABLE feeds DROP COLUMN refresh_interval_minutes;
+ALTER TABLE feeds DROP COLUMN fetch_full_content;
+ALTER TABLE feeds DROP COLUMN retention_read_days;
+ALTER TABLE feeds DROP COLUMN retention_max_items;
+
+CREATE VIEW IF NOT EXISTS user_feeds AS
+SELECT
+  feeds.id,
+  subscriptions.title,
+  feeds.url,
+  subscriptions.created_at,
+  feeds.updated_at,
+  feeds.last_refreshed_at,
+  feeds.image,
+  subscriptions.category_id,
+  feeds.etag,
+  feeds.last_modified,
+  feeds.last_content_length,
+  feeds.bytes_saved,
+  feeds.next_refresh_at,
+  subscriptions.refresh_interval_minutes,
+  feeds.ttl_seconds,
+  feeds.idle_refreshes,
+  feeds.last_error,
+  feeds.last_error_at,
+  feeds.consecutive_failures,
+  feeds.last_http_status,
+  subscriptions.fetch_full_content,
+  subscriptions.retention_read_days,
+  subscriptions.retention_max_items,
+  subscriptions.user_id,
+  feeds.site_url
+FROM feeds
+JOIN subscriptions ON subscriptions.feed_id = feeds.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feeds ADD COLUMN refresh_interval_minutes INTEGER;
+ALTER TABLE feeds ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feeds ADD COLUMN retention_read_days INTEGER;
+ALTER TABLE feeds ADD COLUMN retention_max_items INTEGER;
+
+UPDATE feeds
+SET refresh_interval_minutes = (SELECT MIN(refresh_interval_minutes) FROM subscriptions WHERE feed_id = feeds.id),
+    fetch_full_content = (SELECT COALESCE(MAX(fetch_full_content), FALSE) FROM subscriptions WHERE feed_id = feeds.id),
+    retention_read_days = (SELECT MAX(retention_read_days) FROM subscriptions WHERE feed_id = feeds.id),
+    retention_max_items = (SELECT MAX(retention_max_items) FROM subscriptions WHERE feed_id = feeds.id);
+
+DROP VIEW IF EXISTS user_feeds;
+
+CREATE VIEW IF NOT EXISTS user_feeds AS
+SELECT
+  feeds.id,
+  subscriptions.title,
+  feeds.url,
+  subscriptions.created_at,
+  feeds.updated_at,
+  feeds.last_refreshed_at,
+  feeds.image,
+  subscriptions.category_id,
+  feeds.etag,
+  feeds.last_modified,
+  feeds.last_content_length,
+  feeds.bytes_saved,
+  feeds.next_refresh_at,
+  feeds.refresh_interval_minutes,
+  feeds.ttl_seconds,
+  feeds.idle_refreshes,
+  feeds.last_error,
+  feeds.last_error_at,
+  feeds.consecutive_failures,
+  feeds.last_http_status,
+  feeds.fetch_full_content,
+  feeds.retention_read_days,
+  feeds.retention_max_items,
+  subscriptions.user_id,
+  feeds.site_url
+FROM feeds
+JOIN subscriptions ON subscriptions.feed_id = feeds.id;
+
+ALTER TABLE subscriptions DROP COLUMN retention_max_items;
+ALTER TABLE subscriptions DROP COLUMN retention_read_days;
+ALTER TABLE subscriptions DROP COLUMN fetch_full_content;
+ALTER TABLE subscriptions DROP COLUMN refresh_interval_minutes;
+-- +goose StatementEnd
@@ -9,25 +9,182 @@ import (
 	"time"
 )
 
+type APIToken struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	TokenHash  string
+	FeverKey   string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+}
+
+type Category struct {
+	ID        int64
+	Title     string
+	CreatedAt time.Time
+	UserID    sql.NullInt64
+}
+
 type Feed struct {
-	ID              int64
-	Title           string
-	URL             string
-	CreatedAt       time.Time
-	UpdatedAt       sql.NullTime
-	LastRefreshedAt sql.NullTime
-	Image           sql.NullString
+	ID                  int64
+	Title               string
+	URL                 string
+	CreatedAt           time.Time
+	UpdatedAt           sql.NullTime
+	LastRefreshedAt     sql.NullTime
+	Image               sql.NullString
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	LastContentLength   int64
+	BytesSaved          int64
+	NextRefreshAt       sql.NullTime
+	TTLSeconds          int64
+	IdleRefreshes       int64
+	LastError           sql.NullString
+	LastErrorAt         sql.NullTime
+	ConsecutiveFailures int64
+	LastHTTPStatus      sql.NullInt64
+	SiteURL             sql.NullString
 }
 
 type Item struct {
-	ID          int64
-	FeedID      int64
+	ID               int64
+	FeedID           int64
+	Title            string
+	Link             string
+	Description      string
+	PublishedAt      time.Time
+	CreatedAt        time.Time
+	UpdatedAt        sql.NullTime
+	Hash             string
+	Content          string
+	Author           string
+	Categories       string
+	SourceUpdatedAt  sql.NullTime
+	ExtractedContent string
+	ExtractedAt      sql.NullTime
+}
+
+type ItemState struct {
+	UserID  sql.NullInt64
+	ItemID  int64
+	Status  Status
+	Starred bool
+	Tags    string
+	ReadAt  sql.NullTime
+}
+
+type ItemTombstone struct {
+	FeedID    int64
+	Hash      string
+	DeletedAt sql.NullTime
+}
+
+type ItemsFt struct {
 	Title       string
-	Link        string
 	Description string
-	Status      Status
-	PublishedAt time.Time
-	CreatedAt   time.Time
-	UpdatedAt   sql.NullTime
-	Hash        string
+	Content     string
+}
+
+type ReadAction struct {
+	ID        int64
+	UserID    int64
+	CreatedAt time.Time
+}
+
+type ReadActionItem struct {
+	ActionID int64
+	ItemID   int64
+}
+
+type Rule struct {
+	ID        int64
+	FeedID    sql.NullInt64
+	Field     RuleField
+	MatchType RuleMatch
+	Pattern   string
+	Action    RuleAction
+	Tag       string
+	CreatedAt sql.NullTime
+	UserID    sql.NullInt64
+}
+
+type Session struct {
+	TokenHash string
+	UserID    int64
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type Subscription struct {
+	ID                     int64
+	UserID                 sql.NullInt64
+	FeedID                 int64
+	Title                  string
+	CategoryID             sql.NullInt64
+	CreatedAt              time.Time
+	RefreshIntervalMinutes sql.NullInt64
+	FetchFullContent       bool
+	RetentionReadDays      sql.NullInt64
+	RetentionMaxItems      sql.NullInt64
+}
+
+type User struct {
+	ID               int64
+	Username         string
+	PasswordHash     string
+	CreatedAt        time.Time
+	MarkReadOnOpen   bool
+	MarkReadOnScroll bool
+}
+
+type UserFeed struct {
+	ID                     int64
+	Title                  string
+	URL                    string
+	CreatedAt              time.Time
+	UpdatedAt              sql.NullTime
+	LastRefreshedAt        sql.NullTime
+	Image                  sql.NullString
+	CategoryID             sql.NullInt64
+	Etag                   sql.NullString
+	LastModified           sql.NullString
+	LastContentLength      int64
+	BytesSaved             int64
+	NextRefreshAt          sql.NullTime
+	RefreshIntervalMinutes sql.NullInt64
+	TTLSeconds             int64
+	IdleRefreshes          int64
+	LastError              sql.NullString
+	LastErrorAt            sql.NullTime
+	ConsecutiveFailures    int64
+	LastHTTPStatus         sql.NullInt64
+	FetchFullContent       bool
+	RetentionReadDays      sql.NullInt64
+	RetentionMaxItems      sql.NullInt64
+	UserID                 sql.NullInt64
+	SiteURL                sql.NullString
+}
+
+type UserItem struct {
+	ID               int64
+	FeedID           int64
+	Title            string
+	Link             string
+	Description      string
+	Status           Status
+	PublishedAt      time.Time
+	CreatedAt        time.Time
+	UpdatedAt        sql.NullTime
+	Hash             string
+	Starred          bool
+	Content          string
+	Author           string
+	Categories       string
+	SourceUpdatedAt  sql.NullTime
+	ExtractedContent string
+	ExtractedAt      sql.NullTime
+	Tags             string
+	UserID           sql.NullInt64
 }
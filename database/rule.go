@@ -0,0 +1,53 @@
+package database
+
+type RuleField string
+
+const (
+	RuleFieldTitle    RuleField = "title"
+	RuleFieldLink     RuleField = "link"
+	RuleFieldAuthor   RuleField = "author"
+	RuleFieldContent  RuleField = "content"
+	RuleFieldCategory RuleField = "category"
+)
+
+func AllRuleFieldValues() []RuleField {
+	return []RuleField{
+		RuleFieldTitle,
+		RuleFieldLink,
+		RuleFieldAuthor,
+		RuleFieldContent,
+		RuleFieldCategory,
+	}
+}
+
+type RuleMatch string
+
+const (
+	RuleMatchContains RuleMatch = "contains"
+	RuleMatchRegex    RuleMatch = "regex"
+)
+
+func AllRuleMatchValues() []RuleMatch {
+	return []RuleMatch{
+		RuleMatchContains,
+		RuleMatchRegex,
+	}
+}
+
+type RuleAction string
+
+const (
+	RuleActionDrop     RuleAction = "drop"
+	RuleActionMarkRead RuleAction = "read"
+	RuleActionStar     RuleAction = "star"
+	RuleActionTag      RuleAction = "tag"
+)
+
+func AllRuleActionValues() []RuleAction {
+	return []RuleAction{
+		RuleActionDrop,
+		RuleActionMarkRead,
+		RuleActionStar,
+		RuleActionTag,
+	}
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// The queries in this file are written by hand because sqlc cannot analyze
+// FTS5 MATCH expressions. They only return item IDs, which are then loaded
+// with [Queries.ListItemsByID], so they don't need updating when the items or
+// feeds tables change.
+
+// Snippets returned by [Queries.SearchItems] wrap matched terms in these
+// markers.
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+const searchItems = `
+SELECT items.id, snippet(items_fts, -1, char(2), char(3), '…', 24)
+FROM items_fts
+JOIN user_items AS items ON items.id = items_fts.rowid
+JOIN user_feeds AS feeds ON items.feed_id = feeds.id AND items.user_id = feeds.user_id
+WHERE items_fts MATCH ?1
+AND   items.user_id = ?10
+AND   (CAST (?2 AS BOOL) = 0 OR items.status = ?3)
+AND   (?4 = '' OR feeds.title LIKE '%' || ?4 || '%')
+AND   (?7 = 0 OR items.feed_id = ?7)
+AND   (?8 = 0 OR feeds.category_id = ?8)
+AND   (CAST (?9 AS BOOL) = 0 OR items.starred)
+ORDER BY rank
+LIMIT ?5 OFFSET ?6
+`
+
+const countSearchItems = `
+SELECT COUNT(*)
+FROM items_fts
+JOIN user_items AS items ON items.id = items_fts.rowid
+JOIN user_feeds AS feeds ON items.feed_id = feeds.id AND items.user_id = feeds.user_id
+WHERE items_fts MATCH ?1
+AND   items.user_id = ?8
+AND   (CAST (?2 AS BOOL) = 0 OR items.status = ?3)
+AND   (?4 = '' OR feeds.title LIKE '%' || ?4 || '%')
+AND   (?5 = 0 OR items.feed_id = ?5)
+AND   (?6 = 0 OR feeds.category_id = ?6)
+AND   (CAST (?7 AS BOOL) = 0 OR items.starred)
+`
+
+type SearchItemsParams struct {
+	UserID int64
+	// Match is an FTS5 query expression.
+	Match     string
+	HasStatus bool
+	Status    Status
+	// FeedTitle restricts results to feeds whose title contains it.
+	FeedTitle string
+	// FeedID and CategoryID restrict results to one feed or category when
+	// they are non-zero.
+	FeedID      int64
+	CategoryID  int64
+	StarredOnly bool
+	Limit       int64
+	Offset      int64
+}
+
+type SearchItemsRow struct {
+	ID      int64
+	Snippet string
+}
+
+// SearchItems returns the IDs of items matching arg, best match first.
+func (q *Queries) SearchItems(ctx context.Context, arg SearchItemsParams) ([]SearchItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchItems,
+		arg.Match,
+		arg.HasStatus,
+		arg.Status,
+		arg.FeedTitle,
+		arg.Limit,
+		arg.Offset,
+		arg.FeedID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []SearchItemsRow{}
+	for rows.Next() {
+		var i SearchItemsRow
+		if err := rows.Scan(&i.ID, &i.Snippet); err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search results: %w", err)
+	}
+
+	return items, nil
+}
+
+// CountSearchItems returns the number of items matching arg. Limit and
+// Offset are ignored.
+func (q *Queries) CountSearchItems(ctx context.Context, arg SearchItemsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchItems,
+		arg.Match,
+		arg.HasStatus,
+		arg.Status,
+		arg.FeedTitle,
+		arg.FeedID,
+		arg.CategoryID,
+		arg.StarredOnly,
+		arg.UserID,
+	)
+	var count int64
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting search results: %w", err)
+	}
+
+	return count, nil
+}
@@ -27,10 +27,10 @@ func main() {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	w := worker.New(db, logger)
+	w := worker.New(db, cfg.retention, logger)
 	go w.RunLoop(ctx)
 
-	server := server.New(ctx, cfg.port, db, w, logger)
+	server := server.New(ctx, cfg.port, db, w, cfg.signupEnabled, logger)
 	defer server.Close() //nolint:errcheck
 
 	if err := server.ListenAndServe(); err != nil {
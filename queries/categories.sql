@@ -0,0 +1,25 @@
+-- name: GetCategory :one
+SELECT * FROM categories WHERE id = @id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: GetCategoryByTitle :one
+SELECT * FROM categories WHERE title = @title AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: ListCategories :many
+SELECT * FROM categories WHERE user_id = CAST (@user_id AS INTEGER) ORDER BY title;
+
+-- name: UpsertCategory :one
+INSERT INTO categories(title, user_id) VALUES (@title, CAST (@user_id AS INTEGER))
+ON CONFLICT(user_id, title) DO UPDATE SET title = excluded.title
+RETURNING *;
+
+-- name: DeleteCategory :exec
+DELETE FROM categories WHERE id = @id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: ListCategoryUnreadCounts :many
+SELECT sqlc.embed(categories), COUNT(items.id) AS unread_count FROM categories
+LEFT JOIN subscriptions ON subscriptions.category_id = categories.id
+LEFT JOIN user_items AS items
+    ON items.feed_id = subscriptions.feed_id AND items.user_id = categories.user_id AND items.status = "unread"
+WHERE categories.user_id = CAST (@user_id AS INTEGER)
+GROUP BY categories.id
+ORDER BY categories.title;
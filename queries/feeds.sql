@@ -1,14 +1,97 @@
 -- name: GetFeed :one
 SELECT * FROM feeds WHERE id = ?;
 
+-- name: GetUserFeed :one
+SELECT * FROM user_feeds WHERE id = @id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: GetFeedByURL :one
+SELECT * FROM feeds WHERE url = ?;
+
+-- name: GetUserFeedByURL :one
+SELECT * FROM user_feeds WHERE url = @url AND user_id = CAST (@user_id AS INTEGER);
+
 -- name: ListFeeds :many
+SELECT * FROM user_feeds WHERE user_id = CAST (@user_id AS INTEGER) ORDER BY created_at DESC;
+
+-- name: ListAllFeeds :many
 SELECT * FROM feeds ORDER BY created_at DESC;
 
--- name: CreateFeed :exec
-INSERT INTO feeds(title, url) VALUES (?, ?);
+-- name: ListBrokenFeeds :many
+SELECT * FROM user_feeds
+WHERE user_id = CAST (@user_id AS INTEGER) AND consecutive_failures > 0
+ORDER BY last_error_at DESC;
+
+-- name: ListFeedsDueForRefresh :many
+SELECT * FROM feeds
+WHERE next_refresh_at IS NULL OR next_refresh_at <= CURRENT_TIMESTAMP
+ORDER BY next_refresh_at;
+
+-- name: CreateFeed :one
+INSERT INTO feeds(title, url) VALUES (?, ?) RETURNING *;
+
+-- name: CreateSubscription :exec
+INSERT INTO subscriptions(user_id, feed_id, title, category_id)
+VALUES (CAST (@user_id AS INTEGER), @feed_id, @title, @category_id);
+
+-- name: ListFeedSubscribers :many
+SELECT user_id FROM subscriptions WHERE feed_id = ? ORDER BY id;
 
 -- name: UpdateFeedLastRefreshedAt :exec
 UPDATE feeds SET last_refreshed_at = CURRENT_TIMESTAMP WHERE id = ?;
 
 -- name: UpdateFeedImage :exec
 UPDATE feeds SET image = ? WHERE id = ?;
+
+-- name: UpdateFeedSiteURL :exec
+UPDATE feeds SET site_url = ? WHERE id = ?;
+
+-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds SET etag = ?, last_modified = ?, last_content_length = ? WHERE id = ?;
+
+-- name: RecordFeedNotModified :exec
+UPDATE feeds
+SET bytes_saved = bytes_saved + last_content_length,
+    last_refreshed_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateFeedTTL :exec
+UPDATE feeds SET ttl_seconds = ? WHERE id = ?;
+
+-- name: UpdateFeedSchedule :exec
+UPDATE feeds
+SET next_refresh_at = ?, idle_refreshes = ?
+WHERE id = ?;
+
+-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET last_error = ?,
+    last_error_at = CURRENT_TIMESTAMP,
+    consecutive_failures = consecutive_failures + 1,
+    last_http_status = ?
+WHERE id = ?;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_error = NULL,
+    last_error_at = NULL,
+    consecutive_failures = 0,
+    last_http_status = ?
+WHERE id = ?;
+
+-- name: UpdateSubscription :exec
+UPDATE subscriptions
+SET title = @title,
+    category_id = @category_id,
+    refresh_interval_minutes = @refresh_interval_minutes,
+    fetch_full_content = @fetch_full_content,
+    retention_read_days = @retention_read_days,
+    retention_max_items = @retention_max_items
+WHERE feed_id = @feed_id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: ListSubscriptionSettings :many
+SELECT refresh_interval_minutes, fetch_full_content, retention_read_days, retention_max_items
+FROM subscriptions
+WHERE feed_id = ?;
+
+-- name: DeleteSubscription :exec
+DELETE FROM subscriptions WHERE feed_id = @feed_id AND user_id = CAST (@user_id AS INTEGER);
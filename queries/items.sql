@@ -1,27 +1,239 @@
--- name: CreateItem :exec
-INSERT INTO items(feed_id, title, link, description, hash, published_at) VALUES (?, ?, ?, ?, ?, ?);
+-- name: CreateItem :one
+INSERT INTO items(feed_id, title, link, description, content, author, categories, hash, published_at, source_updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id;
+
+-- name: CreateItemState :exec
+INSERT INTO item_states(user_id, item_id, status, starred, tags) VALUES (?, ?, ?, ?, ?);
+
+-- name: CreateSubscriptionItemStates :exec
+INSERT OR IGNORE INTO item_states(user_id, item_id)
+SELECT CAST (@user_id AS INTEGER), items.id FROM items WHERE items.feed_id = @feed_id;
+
+-- name: CopyFeedItems :exec
+INSERT INTO items(
+  feed_id, title, link, description, content, author, categories, hash,
+  published_at, created_at, source_updated_at, extracted_content, extracted_at
+)
+SELECT
+  CAST (@new_feed_id AS INTEGER), title, link, description, content, author, categories, hash,
+  published_at, created_at, source_updated_at, extracted_content, extracted_at
+FROM items WHERE items.feed_id = @old_feed_id;
+
+-- name: ListMatchingItemStates :many
+SELECT new_items.id AS item_id, item_states.status, item_states.starred, item_states.tags, item_states.read_at
+FROM item_states
+JOIN items AS old_items ON old_items.id = item_states.item_id
+JOIN items AS new_items ON new_items.hash = old_items.hash OR (old_items.link != '' AND new_items.link = old_items.link)
+WHERE item_states.user_id = CAST (@user_id AS INTEGER)
+AND   old_items.feed_id = CAST (@old_feed_id AS INTEGER)
+AND   new_items.feed_id = CAST (@new_feed_id AS INTEGER);
+
+-- name: GetItem :one
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.id = @id AND user_items.user_id = CAST (@user_id AS INTEGER);
 
 -- name: ListItems :many
-SELECT sqlc.embed(items), sqlc.embed(feeds) FROM items
-JOIN feeds ON items.feed_id = feeds.id
-WHERE (CAST (@has_status AS BOOL)  = 0 OR items.status  = @status)
-AND   (CAST (@has_feed_id AS BOOL) = 0 OR items.feed_id = @feed_id)
-ORDER BY items.published_at DESC
-LIMIT ? OFFSET ?;
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_status AS BOOL)           = 0 OR user_items.status      = @status)
+AND   (CAST (@has_feed_id AS BOOL)          = 0 OR user_items.feed_id     = @feed_id)
+AND   (CAST (@has_category_id AS BOOL)      = 0 OR user_feeds.category_id = CAST (@category_id AS INTEGER))
+AND   (CAST (@starred_only AS BOOL)         = 0 OR user_items.starred)
+AND   (CAST (@has_published_after AS BOOL)  = 0 OR user_items.published_at >= @published_after)
+AND   (CAST (@has_published_before AS BOOL) = 0 OR user_items.published_at < @published_before)
+AND   (CAST (@author AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (@author AS TEXT))) > 0)
+AND   user_items.published_at <= @cursor_published_at
+AND   (user_items.published_at < @cursor_published_at OR user_items.id < @cursor_id)
+ORDER BY user_items.published_at DESC, user_items.id DESC
+LIMIT @limit;
+
+-- name: ListItemsOldestFirst :many
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_status AS BOOL)           = 0 OR user_items.status      = @status)
+AND   (CAST (@has_feed_id AS BOOL)          = 0 OR user_items.feed_id     = @feed_id)
+AND   (CAST (@has_category_id AS BOOL)      = 0 OR user_feeds.category_id = CAST (@category_id AS INTEGER))
+AND   (CAST (@starred_only AS BOOL)         = 0 OR user_items.starred)
+AND   (CAST (@has_published_after AS BOOL)  = 0 OR user_items.published_at >= @published_after)
+AND   (CAST (@has_published_before AS BOOL) = 0 OR user_items.published_at < @published_before)
+AND   (CAST (@author AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (@author AS TEXT))) > 0)
+AND   user_items.published_at >= @cursor_published_at
+AND   (user_items.published_at > @cursor_published_at OR user_items.id > @cursor_id)
+ORDER BY user_items.published_at ASC, user_items.id ASC
+LIMIT @limit;
+
+-- name: ListItemsByFetchedAt :many
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_status AS BOOL)           = 0 OR user_items.status      = @status)
+AND   (CAST (@has_feed_id AS BOOL)          = 0 OR user_items.feed_id     = @feed_id)
+AND   (CAST (@has_category_id AS BOOL)      = 0 OR user_feeds.category_id = CAST (@category_id AS INTEGER))
+AND   (CAST (@starred_only AS BOOL)         = 0 OR user_items.starred)
+AND   (CAST (@has_published_after AS BOOL)  = 0 OR user_items.published_at >= @published_after)
+AND   (CAST (@has_published_before AS BOOL) = 0 OR user_items.published_at < @published_before)
+AND   (CAST (@author AS TEXT) = '' OR instr(LOWER(user_items.author), LOWER(CAST (@author AS TEXT))) > 0)
+AND   user_items.created_at <= CAST (@cursor_created_at AS TEXT)
+AND   (user_items.created_at < CAST (@cursor_created_at AS TEXT) OR user_items.id < @cursor_id)
+ORDER BY user_items.created_at DESC, user_items.id DESC
+LIMIT @limit;
 
 -- name: CountItems :one
-SELECT COUNT(*) FROM items
-WHERE (CAST (@has_status AS BOOL)  = 0 OR items.status  = @status)
-AND   (CAST (@has_feed_id AS BOOL) = 0 OR items.feed_id = @feed_id);
+SELECT COUNT(*) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_status AS BOOL)      = 0 OR user_items.status      = @status)
+AND   (CAST (@has_feed_id AS BOOL)     = 0 OR user_items.feed_id     = @feed_id)
+AND   (CAST (@has_category_id AS BOOL) = 0 OR user_feeds.category_id = CAST (@category_id AS INTEGER))
+AND   (CAST (@starred_only AS BOOL)    = 0 OR user_items.starred);
 
 -- name: UpdateItem :exec
-UPDATE items SET title = ?, link = ?, description = ?, published_at = ? WHERE id = ?;
+UPDATE items
+SET title = @title,
+    link = @link,
+    description = @description,
+    content = @content,
+    author = @author,
+    categories = @categories,
+    published_at = @published_at,
+    source_updated_at = @source_updated_at,
+    extracted_content = CASE WHEN link = @link THEN extracted_content ELSE '' END,
+    extracted_at = CASE WHEN link = @link THEN extracted_at ELSE NULL END
+WHERE id = @id;
+
+-- name: ListItemsToExtract :many
+SELECT * FROM items
+WHERE feed_id = ? AND extracted_at IS NULL AND link != ''
+ORDER BY published_at DESC
+LIMIT ?;
+
+-- name: UpdateItemExtractedContent :exec
+UPDATE items SET extracted_content = ?, extracted_at = ? WHERE id = ?;
+
+-- name: UpdateItemStatus :exec
+UPDATE item_states SET status = @status
+WHERE item_id = @id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: UpdateItemStarred :exec
+UPDATE item_states SET starred = @starred
+WHERE item_id = @id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: UpdateItemState :exec
+UPDATE item_states SET status = @status, starred = @starred, tags = @tags
+WHERE item_id = @id AND user_id = CAST (@user_id AS INTEGER);
 
--- name: UpdateItemStatus :one
-UPDATE items SET status = ? WHERE id = ? RETURNING *;
+-- name: UpdateItemReadAt :exec
+UPDATE item_states SET read_at = CAST (@read_at AS TEXT)
+WHERE item_id = @id AND user_id = CAST (@user_id AS INTEGER);
 
--- name: MarkAllItemsAsRead :exec
-UPDATE items SET status = "read" WHERE status = "unread";
+-- name: UpdateItemsStatus :exec
+UPDATE item_states SET status = @status
+WHERE user_id = CAST (@user_id AS INTEGER) AND item_id IN (sqlc.slice(ids));
 
 -- name: CheckItemExists :one
 SELECT * FROM items WHERE feed_id = ? AND hash = ?;
+
+-- name: ListItemsByID :many
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER) AND user_items.id IN (sqlc.slice(ids));
+
+-- name: ListItemsAfterID :many
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.id > @id AND user_items.user_id = CAST (@user_id AS INTEGER)
+ORDER BY user_items.id
+LIMIT @limit;
+
+-- name: ListItemsBeforeID :many
+SELECT sqlc.embed(user_items), sqlc.embed(user_feeds) FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.id < @id AND user_items.user_id = CAST (@user_id AS INTEGER)
+ORDER BY user_items.id DESC
+LIMIT @limit;
+
+-- name: ListRecentItems :many
+SELECT items.* FROM items
+JOIN subscriptions ON items.feed_id = subscriptions.feed_id
+WHERE subscriptions.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_feed AS BOOL) = 0 OR items.feed_id = @feed_id)
+ORDER BY items.published_at DESC
+LIMIT @limit;
+
+-- name: ListItemsPastRetention :many
+SELECT items.id FROM items
+WHERE items.feed_id = @feed_id
+AND   NOT EXISTS (SELECT 1 FROM item_states WHERE item_states.item_id = items.id AND item_states.starred)
+AND   (
+    (CAST (@has_read_cutoff AS BOOL) = 1 AND items.id NOT IN (
+        SELECT item_states.item_id FROM item_states
+        WHERE item_states.item_id = items.id
+        AND   (item_states.status = "unread" OR item_states.read_at >= CAST (@read_cutoff AS TEXT))
+    ))
+    OR (CAST (@has_max_items AS BOOL) = 1 AND items.id NOT IN (
+        SELECT newest.id FROM items AS newest
+        WHERE newest.feed_id = @feed_id
+        ORDER BY newest.published_at DESC, newest.id DESC
+        LIMIT @max_items
+    ))
+);
+
+-- name: CreateItemTombstones :exec
+INSERT OR IGNORE INTO item_tombstones (feed_id, hash)
+SELECT feed_id, hash FROM items WHERE id IN (sqlc.slice(ids));
+
+-- name: DeleteItems :exec
+DELETE FROM items WHERE id IN (sqlc.slice(ids));
+
+-- name: CheckItemTombstoneExists :one
+SELECT EXISTS (SELECT 1 FROM item_tombstones WHERE feed_id = ? AND hash = ?);
+
+-- name: ListItemIDs :many
+SELECT user_items.id FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_status AS BOOL)      = 0 OR user_items.status       = @status)
+AND   (CAST (@has_feed_id AS BOOL)     = 0 OR user_items.feed_id      = @feed_id)
+AND   (CAST (@has_category_id AS BOOL) = 0 OR user_feeds.category_id  = CAST (@category_id AS INTEGER))
+AND   (CAST (@starred_only AS BOOL)    = 0 OR user_items.starred)
+AND   (CAST (@has_newer_than AS BOOL)  = 0 OR user_items.published_at > @newer_than)
+AND   (CAST (@has_older_than AS BOOL)  = 0 OR user_items.published_at < @older_than)
+ORDER BY user_items.published_at DESC, user_items.id DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListItemIDsOldestFirst :many
+SELECT user_items.id FROM user_items
+JOIN user_feeds ON user_items.feed_id = user_feeds.id AND user_items.user_id = user_feeds.user_id
+WHERE user_items.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_status AS BOOL)      = 0 OR user_items.status       = @status)
+AND   (CAST (@has_feed_id AS BOOL)     = 0 OR user_items.feed_id      = @feed_id)
+AND   (CAST (@has_category_id AS BOOL) = 0 OR user_feeds.category_id  = CAST (@category_id AS INTEGER))
+AND   (CAST (@starred_only AS BOOL)    = 0 OR user_items.starred)
+AND   (CAST (@has_newer_than AS BOOL)  = 0 OR user_items.published_at > @newer_than)
+AND   (CAST (@has_older_than AS BOOL)  = 0 OR user_items.published_at < @older_than)
+ORDER BY user_items.published_at ASC, user_items.id ASC
+LIMIT @limit OFFSET @offset;
+
+-- name: MarkItemsAsRead :exec
+UPDATE item_states SET status = "read"
+WHERE item_states.status = "unread"
+AND   item_states.user_id = CAST (@user_id AS INTEGER)
+AND   item_states.item_id IN (
+    SELECT items.id FROM items
+    JOIN subscriptions ON subscriptions.feed_id = items.feed_id
+    WHERE subscriptions.user_id = CAST (@user_id AS INTEGER)
+    AND   (CAST (@has_feed_id AS BOOL)     = 0 OR items.feed_id = @feed_id)
+    AND   (CAST (@has_category_id AS BOOL) = 0 OR subscriptions.category_id = CAST (@category_id AS INTEGER))
+    AND   (CAST (@has_older_than AS BOOL)  = 0 OR items.published_at <= @older_than)
+)
+AND   (CAST (@starred_only AS BOOL) = 0 OR item_states.starred);
+
+-- name: ListFeedUnreadCounts :many
+SELECT user_items.feed_id, COUNT(*) AS unread_count, CAST (MAX(user_items.published_at) AS TEXT) AS newest_published_at
+FROM user_items
+WHERE user_items.status = "unread" AND user_items.user_id = CAST (@user_id AS INTEGER)
+GROUP BY user_items.feed_id;
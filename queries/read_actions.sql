@@ -0,0 +1,50 @@
+-- name: CreateReadAction :one
+INSERT INTO read_actions (user_id) VALUES (CAST (@user_id AS INTEGER))
+RETURNING id;
+
+-- name: AddReadActionItems :exec
+INSERT INTO read_action_items (action_id, item_id)
+SELECT CAST (@action_id AS INTEGER), item_states.item_id FROM item_states
+JOIN items ON items.id = item_states.item_id
+JOIN subscriptions ON subscriptions.feed_id = items.feed_id AND subscriptions.user_id = item_states.user_id
+WHERE item_states.status = "unread"
+AND   item_states.user_id = CAST (@user_id AS INTEGER)
+AND   (CAST (@has_feed_id AS BOOL)     = 0 OR items.feed_id = CAST (@feed_id AS INTEGER))
+AND   (CAST (@has_category_id AS BOOL) = 0 OR subscriptions.category_id = CAST (@category_id AS INTEGER))
+AND   (CAST (@has_older_than AS BOOL)  = 0 OR items.published_at <= @older_than)
+AND   (CAST (@has_max_id AS BOOL)      = 0 OR items.id <= CAST (@max_id AS INTEGER));
+
+-- name: ApplyReadAction :exec
+UPDATE item_states SET status = "read"
+WHERE item_states.user_id = CAST (@user_id AS INTEGER)
+AND   item_states.item_id IN (
+    SELECT read_action_items.item_id FROM read_action_items
+    WHERE read_action_items.action_id = CAST (@action_id AS INTEGER)
+);
+
+-- name: GetReadAction :one
+SELECT read_actions.id, COUNT(read_action_items.item_id) AS item_count FROM read_actions
+LEFT JOIN read_action_items ON read_action_items.action_id = read_actions.id
+WHERE read_actions.id = CAST (@id AS INTEGER) AND read_actions.user_id = CAST (@user_id AS INTEGER)
+GROUP BY read_actions.id;
+
+-- name: UndoReadAction :exec
+UPDATE item_states SET status = "unread"
+WHERE item_states.status = "read"
+AND   item_states.user_id = CAST (@user_id AS INTEGER)
+AND   item_states.item_id IN (
+    SELECT read_action_items.item_id FROM read_action_items
+    JOIN read_actions ON read_actions.id = read_action_items.action_id
+    WHERE read_actions.id = CAST (@id AS INTEGER) AND read_actions.user_id = CAST (@user_id AS INTEGER)
+);
+
+-- name: DeleteReadAction :exec
+DELETE FROM read_actions
+WHERE read_actions.id = CAST (@id AS INTEGER) AND read_actions.user_id = CAST (@user_id AS INTEGER);
+
+-- name: DeleteOldReadActions :exec
+DELETE FROM read_actions
+WHERE read_actions.user_id = CAST (@user_id AS INTEGER) AND read_actions.created_at < CAST (@before AS TEXT);
+
+-- name: GetMaxItemID :one
+SELECT CAST (COALESCE(MAX(items.id), 0) AS INTEGER) AS max_id FROM items;
@@ -0,0 +1,26 @@
+-- name: ListRules :many
+SELECT rules.*, COALESCE(user_feeds.title, '') AS feed_title FROM rules
+LEFT JOIN user_feeds ON rules.feed_id = user_feeds.id AND rules.user_id = user_feeds.user_id
+WHERE rules.user_id = CAST (@user_id AS INTEGER)
+ORDER BY rules.feed_id IS NOT NULL, feed_title, rules.id;
+
+-- name: ListRulesForFeed :many
+SELECT * FROM rules
+WHERE rules.feed_id = CAST (@feed_id AS INTEGER)
+OR (rules.feed_id IS NULL AND EXISTS (
+    SELECT 1 FROM subscriptions
+    WHERE subscriptions.feed_id = CAST (@feed_id AS INTEGER) AND subscriptions.user_id IS rules.user_id
+))
+ORDER BY rules.id;
+
+-- name: CreateRule :one
+INSERT INTO rules (feed_id, field, match_type, pattern, action, tag, user_id)
+VALUES (@feed_id, @field, @match_type, @pattern, @action, @tag, CAST (@user_id AS INTEGER))
+RETURNING *;
+
+-- name: DeleteRule :exec
+DELETE FROM rules WHERE id = @id AND user_id = CAST (@user_id AS INTEGER);
+
+-- name: MoveFeedRules :exec
+UPDATE rules SET feed_id = @new_feed_id
+WHERE feed_id = @old_feed_id AND user_id = CAST (@user_id AS INTEGER);
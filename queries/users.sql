@@ -0,0 +1,59 @@
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: CreateUser :one
+INSERT INTO users(username, password_hash) VALUES (?, ?) RETURNING *;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users WHERE username = ?;
+
+-- name: UpdateUserReadSettings :exec
+UPDATE users SET mark_read_on_open = ?, mark_read_on_scroll = ? WHERE id = ?;
+
+-- name: ClaimUnownedSubscriptions :exec
+UPDATE subscriptions SET user_id = CAST (@user_id AS INTEGER) WHERE user_id IS NULL;
+
+-- name: ClaimUnownedItemStates :exec
+UPDATE item_states SET user_id = CAST (@user_id AS INTEGER) WHERE user_id IS NULL;
+
+-- name: ClaimUnownedCategories :exec
+UPDATE categories SET user_id = CAST (@user_id AS INTEGER) WHERE user_id IS NULL;
+
+-- name: ClaimUnownedRules :exec
+UPDATE rules SET user_id = CAST (@user_id AS INTEGER) WHERE user_id IS NULL;
+
+-- name: CreateSession :exec
+INSERT INTO sessions(token_hash, user_id, expires_at) VALUES (?, ?, ?);
+
+-- name: GetSessionUser :one
+SELECT sqlc.embed(users) FROM sessions
+JOIN users ON sessions.user_id = users.id
+WHERE sessions.token_hash = @token_hash AND sessions.expires_at > @now;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = ?;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires_at <= ?;
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens(user_id, name, token_hash, fever_key) VALUES (?, ?, ?, ?) RETURNING *;
+
+-- name: ListAPITokens :many
+SELECT * FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id DESC;
+
+-- name: DeleteAPIToken :exec
+DELETE FROM api_tokens WHERE id = ? AND user_id = ?;
+
+-- name: GetAPITokenUser :one
+SELECT sqlc.embed(users) FROM api_tokens
+JOIN users ON api_tokens.user_id = users.id
+WHERE api_tokens.token_hash = ?;
+
+-- name: GetFeverKeyUser :one
+SELECT sqlc.embed(users) FROM api_tokens
+JOIN users ON api_tokens.user_id = users.id
+WHERE api_tokens.fever_key = ?;
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE token_hash = ?;
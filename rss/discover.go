@@ -0,0 +1,165 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxDiscoveryBodySize = 10 << 20 // 10 MiB
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid URL")
+	ErrUnexpectedStatus = errors.New("unexpected status")
+	ErrNoFeedsFound     = errors.New("no feeds found")
+)
+
+var feedLinkTypes = []string{
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/feed+json",
+	"application/json",
+}
+
+// FeedLink is a feed URL found while discovering feeds from a page.
+type FeedLink struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+// DiscoverFeeds returns the feeds available at rawURL. If rawURL points
+// directly to a feed it is returned as the only result, otherwise the page is
+// parsed as HTML and any <link rel="alternate"> feed links are returned.
+func DiscoverFeeds(ctx context.Context, rawURL string) ([]FeedLink, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDiscoveryBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	// Follow redirects so relative links resolve against the final page.
+	base := resp.Request.URL
+
+	switch gofeed.DetectFeedType(bytes.NewReader(body)) {
+	case gofeed.FeedTypeRSS:
+		return []FeedLink{{URL: base.String(), Type: "application/rss+xml"}}, nil
+	case gofeed.FeedTypeAtom:
+		return []FeedLink{{URL: base.String(), Type: "application/atom+xml"}}, nil
+	case gofeed.FeedTypeJSON:
+		return []FeedLink{{URL: base.String(), Type: "application/feed+json"}}, nil
+	case gofeed.FeedTypeUnknown:
+	}
+
+	links, err := parseFeedLinks(bytes.NewReader(body), base)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(links) == 0 {
+		return nil, ErrNoFeedsFound
+	}
+
+	return links, nil
+}
+
+func parseFeedLinks(r io.Reader, base *url.URL) ([]FeedLink, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	links := []FeedLink{}
+	seen := map[string]bool{}
+
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+
+		switch n.DataAtom {
+		case atom.Base:
+			if href := getAttr(n, "href"); href != "" {
+				if u, err := base.Parse(href); err == nil {
+					base = u
+				}
+			}
+		case atom.Link:
+			rel := strings.Fields(strings.ToLower(getAttr(n, "rel")))
+			if !slices.Contains(rel, "alternate") {
+				continue
+			}
+
+			linkType := strings.ToLower(strings.TrimSpace(getAttr(n, "type")))
+			if !slices.Contains(feedLinkTypes, linkType) {
+				continue
+			}
+
+			href := getAttr(n, "href")
+			if href == "" {
+				continue
+			}
+
+			u, err := base.Parse(href)
+			if err != nil || seen[u.String()] {
+				continue
+			}
+			seen[u.String()] = true
+
+			links = append(links, FeedLink{
+				URL:   u.String(),
+				Title: getAttr(n, "title"),
+				Type:  linkType,
+			})
+		}
+	}
+
+	return links, nil
+}
+
+func getAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,110 @@
+package rss
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	gofeedrss "github.com/mmcdole/gofeed/rss"
+)
+
+const customKeyTTL = "ttl"
+
+// ttlTranslator keeps the RSS <ttl> element, which the default translator
+// drops, in Feed.Custom.
+type ttlTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *ttlTranslator) Translate(feed any) (*gofeed.Feed, error) {
+	f, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if rssFeed, ok := feed.(*gofeedrss.Feed); ok && rssFeed.TTL != "" {
+		if f.Custom == nil {
+			f.Custom = map[string]string{}
+		}
+		f.Custom[customKeyTTL] = rssFeed.TTL
+	}
+
+	return f, nil
+}
+
+func newParser() *gofeed.Parser {
+	p := gofeed.NewParser()
+	p.RSSTranslator = &ttlTranslator{}
+	return p
+}
+
+var syUpdatePeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// feedTTL returns how long the publisher asks readers to wait between
+// refreshes, based on the RSS <ttl> and the syndication module's
+// sy:updatePeriod and sy:updateFrequency. It returns 0 if the feed gives no hint.
+func feedTTL(feed *gofeed.Feed) time.Duration {
+	var ttl time.Duration
+
+	if minutes, err := strconv.Atoi(strings.TrimSpace(feed.Custom[customKeyTTL])); err == nil && minutes > 0 {
+		ttl = time.Duration(minutes) * time.Minute
+	}
+
+	sy := feed.Extensions["sy"]
+	if periods := sy["updatePeriod"]; len(periods) > 0 {
+		if period, ok := syUpdatePeriods[strings.ToLower(strings.TrimSpace(periods[0].Value))]; ok {
+			frequency := 1
+			if frequencies := sy["updateFrequency"]; len(frequencies) > 0 {
+				if f, err := strconv.Atoi(strings.TrimSpace(frequencies[0].Value)); err == nil && f > 0 {
+					frequency = f
+				}
+			}
+			ttl = max(ttl, period/time.Duration(frequency))
+		}
+	}
+
+	return ttl
+}
+
+// maxAge returns the max-age directive of a Cache-Control header, or 0.
+func maxAge(header http.Header) time.Duration {
+	for directive := range strings.SplitSeq(header.Get("Cache-Control"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+
+		if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return 0
+}
+
+// retryAfter returns the delay requested by a Retry-After header, which may
+// be either a number of seconds or an HTTP date, or 0.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+
+	return 0
+}
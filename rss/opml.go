@@ -0,0 +1,132 @@
+package rss
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	opmlVersion = "2.0"
+	// categorySeparator joins the titles of nested outlines into the name of a
+	// single category, since categories can't be nested.
+	categorySeparator = " / "
+)
+
+type opml struct {
+	XMLName xml.Name    `xml:"opml"`
+	Version string      `xml:"version,attr"`
+	Head    opmlHead    `xml:"head"`
+	Body    opmlOutline `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr,omitempty"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (o opmlOutline) name() string {
+	return strings.TrimSpace(cmp.Or(o.Title, o.Text))
+}
+
+// Subscription is a single feed in an OPML document. Category is the path of
+// the outlines the feed is nested in, if any, with the titles of nested
+// outlines joined by categorySeparator, like "Parent / Child".
+type Subscription struct {
+	Title    string
+	URL      string
+	Category string
+}
+
+// ParseOPML returns the feeds listed in an OPML document.
+func ParseOPML(r io.Reader) ([]Subscription, error) {
+	var doc opml
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding OPML: %w", err)
+	}
+
+	subs := []Subscription{}
+	collectSubscriptions(doc.Body.Outlines, "", &subs)
+
+	return subs, nil
+}
+
+func collectSubscriptions(outlines []opmlOutline, category string, subs *[]Subscription) {
+	for _, o := range outlines {
+		if url := strings.TrimSpace(o.XMLURL); url != "" {
+			*subs = append(*subs, Subscription{Title: o.name(), URL: url, Category: category})
+			collectSubscriptions(o.Outlines, category, subs)
+			continue
+		}
+
+		collectSubscriptions(o.Outlines, categoryPath(category, o.name()), subs)
+	}
+}
+
+// categoryPath returns the category of the outlines nested in an outline named
+// name, in category.
+func categoryPath(category, name string) string {
+	if category == "" || name == "" {
+		return cmp.Or(name, category)
+	}
+	return category + categorySeparator + name
+}
+
+// WriteOPML writes subs as an OPML 2.0 document, nesting feeds under an
+// outline per category.
+func WriteOPML(w io.Writer, title string, subs []Subscription) error {
+	doc := opml{
+		Version: opmlVersion,
+		Head: opmlHead{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	categories := map[string]*opmlOutline{}
+	for _, sub := range subs {
+		o := opmlOutline{Text: sub.Title, Title: sub.Title, Type: "rss", XMLURL: sub.URL}
+
+		if sub.Category == "" {
+			doc.Body.Outlines = append(doc.Body.Outlines, o)
+			continue
+		}
+
+		c, ok := categories[sub.Category]
+		if !ok {
+			c = &opmlOutline{Text: sub.Category, Title: sub.Category}
+			categories[sub.Category] = c
+		}
+		c.Outlines = append(c.Outlines, o)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(categories)) {
+		doc.Body.Outlines = append(doc.Body.Outlines, *categories[name])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("writing XML header: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding OPML: %w", err)
+	}
+
+	return nil
+}
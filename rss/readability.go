@@ -0,0 +1,221 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxArticleBodySize = 10 << 20 // 10 MiB
+	// minParagraphLength is the shortest paragraph that counts towards the
+	// score of its ancestors.
+	minParagraphLength = 25
+	// minArticleLength is the shortest extracted text that is considered an
+	// article rather than boilerplate.
+	minArticleLength = 250
+	charsPerPoint    = 100
+	maxLengthPoints  = 3
+	classWeight      = 25
+)
+
+var ErrNoArticleFound = errors.New("no article found")
+
+var (
+	positiveCandidate = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text`)
+	negativeCandidate = regexp.MustCompile(`(?i)ad-|ads|banner|comment|combx|contact|footer|masthead|menu|meta|nav|promo|related|share|shoutbox|sidebar|social|sponsor|widget`)
+)
+
+// unlikelyTags never contain the main article body.
+var unlikelyTags = []atom.Atom{
+	atom.Aside,
+	atom.Footer,
+	atom.Form,
+	atom.Header,
+	atom.Nav,
+	atom.Noscript,
+	atom.Script,
+	atom.Style,
+}
+
+// ExtractArticle downloads the page at rawURL and returns its main article
+// body as sanitized HTML, using a readability-style heuristic: paragraphs
+// award points to their parent and grandparent based on their length, and the
+// highest scoring element, adjusted for link density and class names, wins.
+func ExtractArticle(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	doc, err := html.Parse(io.LimitReader(resp.Body, maxArticleBodySize))
+	if err != nil {
+		return "", fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	article := findArticle(doc)
+	if article == nil {
+		return "", ErrNoArticleFound
+	}
+
+	var b strings.Builder
+	for c := range article.ChildNodes() {
+		if err := html.Render(&b, c); err != nil {
+			return "", fmt.Errorf("rendering article: %w", err)
+		}
+	}
+
+	return sanitizeHTML(b.String(), resp.Request.URL), nil
+}
+
+// findArticle returns the element of doc most likely to hold the article
+// body, or nil if nothing looks like an article.
+func findArticle(doc *html.Node) *html.Node {
+	pruneUnlikely(doc)
+
+	scores := map[*html.Node]float64{}
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || (n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Td) {
+			continue
+		}
+
+		text := strings.TrimSpace(textContent(n))
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(length/charsPerPoint), maxLengthPoints)
+
+		if parent := n.Parent; parent != nil && parent.Type == html.ElementNode {
+			if _, ok := scores[parent]; !ok {
+				scores[parent] = classScore(parent)
+			}
+			scores[parent] += score
+
+			if grandparent := parent.Parent; grandparent != nil && grandparent.Type == html.ElementNode {
+				if _, ok := scores[grandparent]; !ok {
+					scores[grandparent] = classScore(grandparent)
+				}
+				scores[grandparent] += score / 2 //nolint:mnd
+			}
+		}
+	}
+
+	// Candidates are visited in document order rather than map order, so ties
+	// go to the element that comes first.
+	var best *html.Node
+	var bestScore float64
+	for n := range doc.Descendants() {
+		score, ok := scores[n]
+		if !ok {
+			continue
+		}
+		score *= 1 - linkDensity(n)
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+
+	if best == nil || utf8.RuneCountInString(strings.TrimSpace(textContent(best))) < minArticleLength {
+		return nil
+	}
+
+	return best
+}
+
+// pruneUnlikely removes elements that never hold the article body.
+func pruneUnlikely(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			for _, tag := range unlikelyTags {
+				if c.DataAtom == tag {
+					n.RemoveChild(c)
+					break
+				}
+			}
+		}
+		if c.Parent == n {
+			pruneUnlikely(c)
+		}
+		c = next
+	}
+}
+
+// classScore scores n by whether its class and id look like article content.
+func classScore(n *html.Node) float64 {
+	var score float64
+	for _, name := range []string{getAttr(n, "class"), getAttr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeCandidate.MatchString(name) {
+			score -= classWeight
+		}
+		if positiveCandidate.MatchString(name) {
+			score += classWeight
+		}
+	}
+
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score += classWeight
+	case atom.Div:
+		score += 5 //nolint:mnd
+	}
+
+	return score
+}
+
+// linkDensity is the fraction of n's text that is inside links.
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(textContent(n))
+	if length == 0 {
+		return 0
+	}
+
+	var linkLength int
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.DataAtom == atom.A {
+			linkLength += utf8.RuneCountInString(textContent(d))
+		}
+	}
+
+	return float64(linkLength) / float64(length)
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package rss
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func newArticleServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle("/articles/", http.StripPrefix("/articles/", http.FileServer(http.Dir("testdata/articles"))))
+	mux.Handle("/moved", http.RedirectHandler("/articles/blog.html", http.StatusMovedPermanently))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestExtractArticle(t *testing.T) {
+	srv := newArticleServer(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "blog post",
+			path: "/articles/blog.html",
+			want: []string{
+				"The first paragraph of the story",
+				"The fourth paragraph wraps things up",
+				`<a href="` + srv.URL + `/related/1" rel="noopener noreferrer" target="_blank">a related post</a>`,
+				`<img src="` + srv.URL + `/articles/images/chart.png" alt="a chart"/>`,
+			},
+			notWant: []string{
+				"Site header",
+				"Archive",
+				"About the author",
+				"A comment from a reader",
+				"Copyright notice",
+				"<script",
+				"alert",
+				"onerror",
+				"font-family",
+			},
+		},
+		{
+			name: "relative URLs are resolved against the redirected URL",
+			path: "/moved",
+			want: []string{
+				`<img src="` + srv.URL + `/articles/images/chart.png" alt="a chart"/>`,
+			},
+		},
+		{
+			name: "links are penalized",
+			path: "/articles/links.html",
+			want: []string{
+				"The actual article text is here",
+				"A third paragraph of the article",
+			},
+			notWant: []string{
+				"A link whose text",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractArticle(t.Context(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatalf("ExtractArticle() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("ExtractArticle() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("ExtractArticle() = %q, want it not to contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestFindArticleTie(t *testing.T) {
+	// The winner used to be picked in map order, so check enough times that a
+	// random order would show.
+	for range 50 {
+		f, err := os.Open("testdata/articles/tie.html")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := html.Parse(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		article := findArticle(doc)
+		if article == nil {
+			t.Fatal("findArticle() = nil, want the first of the tied elements")
+		}
+		if got := getAttr(article, "id"); got != "first" {
+			t.Fatalf("findArticle() picked %q, want the first of the tied elements", got)
+		}
+	}
+}
+
+func TestExtractArticleErrors(t *testing.T) {
+	srv := newArticleServer(t)
+
+	t.Run("too short", func(t *testing.T) {
+		_, err := ExtractArticle(t.Context(), srv.URL+"/articles/short.html")
+		if !errors.Is(err, ErrNoArticleFound) {
+			t.Errorf("ExtractArticle() error = %v, want %v", err, ErrNoArticleFound)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := ExtractArticle(t.Context(), srv.URL+"/articles/missing.html")
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+			t.Errorf("ExtractArticle() error = %v, want a 404 HTTPError", err)
+		}
+	})
+}
@@ -1,21 +1,132 @@
 package rss
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/ethansaxenian/rss/database"
 	"github.com/mmcdole/gofeed"
 )
 
+const (
+	userAgent          = "rss/1.0"
+	maxFeedBodySize    = 50 << 20 // 50 MiB
+	headerETag         = "ETag"
+	headerLastModified = "Last-Modified"
+)
+
+// FetchResult is the result of a conditional feed fetch. Feed is nil when the
+// server responded with 304 Not Modified.
+type FetchResult struct {
+	Feed          *gofeed.Feed
+	StatusCode    int
+	NotModified   bool
+	ETag          string
+	LastModified  string
+	ContentLength int64
+	// TTL is the refresh interval declared by the feed itself. It is only set
+	// when Feed is.
+	TTL time.Duration
+	// MaxAge is the max-age of the response's Cache-Control header.
+	MaxAge time.Duration
+}
+
+// HTTPError is returned when a feed responds with a non-2xx status.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrUnexpectedStatus, e.Status)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return ErrUnexpectedStatus
+}
+
 func FetchFeed(ctx context.Context, url string) (*gofeed.Feed, error) {
-	return gofeed.NewParser().ParseURLWithContext(url, ctx) //nolint: wrapcheck
+	res, err := FetchFeedConditional(ctx, url, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Feed, nil
+}
+
+// FetchFeedConditional fetches and parses the feed at url, sending
+// If-None-Match and If-Modified-Since when etag or lastModified are set.
+func FetchFeedConditional(ctx context.Context, url, etag, lastModified string) (FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("building request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return FetchResult{
+			StatusCode:   resp.StatusCode,
+			NotModified:  true,
+			ETag:         cmp.Or(resp.Header.Get(headerETag), etag),
+			LastModified: cmp.Or(resp.Header.Get(headerLastModified), lastModified),
+			MaxAge:       maxAge(resp.Header),
+		}, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return FetchResult{}, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: retryAfter(resp.Header, time.Now()),
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBodySize))
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("reading response body: %w", err)
+	}
+
+	feed, err := newParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("parsing feed: %w", err)
+	}
+
+	return FetchResult{
+		Feed:          feed,
+		StatusCode:    resp.StatusCode,
+		ETag:          resp.Header.Get(headerETag),
+		LastModified:  resp.Header.Get(headerLastModified),
+		ContentLength: int64(len(body)),
+		TTL:           feedTTL(feed),
+		MaxAge:        maxAge(resp.Header),
+	}, nil
 }
 
 func GetItemHash(item *gofeed.Item) string {
@@ -34,34 +145,140 @@ func GetItemHash(item *gofeed.Item) string {
 	return hash
 }
 
-func shouldUpdateItem(item *gofeed.Item, existingItem database.Item) bool {
-	return item.Title != existingItem.Title ||
-		item.Link != existingItem.Link ||
-		item.Description != existingItem.Description ||
-		*item.PublishedParsed != existingItem.PublishedAt
+// itemFields are the values stored for a feed item.
+type itemFields struct {
+	title       string
+	link        string
+	description string
+	content     string
+	author      string
+	categories  string
+	// publishedAt is the zero time if the item has no publish or update date.
+	publishedAt time.Time
+	updatedAt   sql.NullTime
+}
+
+// newItemFields returns the values to store for item. Its HTML is sanitized,
+// with relative URLs resolved against the item's link or else feedBase.
+func newItemFields(item *gofeed.Item, feedBase *url.URL) itemFields {
+	base := feedBase
+	if link, err := url.Parse(item.Link); err == nil {
+		if base != nil {
+			link = base.ResolveReference(link)
+		}
+		if link.IsAbs() {
+			base = link
+		}
+	}
+
+	// The link is rendered as an href, so like links in content only http
+	// and https URLs are kept.
+	var link string
+	if strings.TrimSpace(item.Link) != "" {
+		link, _ = sanitizeURL(item.Link, feedBase, false)
+	}
+
+	fields := itemFields{
+		title:       item.Title,
+		link:        link,
+		description: sanitizeHTML(item.Description, base),
+		content:     sanitizeHTML(item.Content, base),
+		author:      itemAuthor(item),
+		categories:  "[]",
+	}
+
+	if len(item.Categories) > 0 {
+		if categories, err := json.Marshal(item.Categories); err == nil {
+			fields.categories = string(categories)
+		}
+	}
+
+	switch {
+	case item.PublishedParsed != nil:
+		fields.publishedAt = item.PublishedParsed.UTC() // Store as UTC
+	case item.UpdatedParsed != nil:
+		fields.publishedAt = item.UpdatedParsed.UTC()
+	}
+
+	if item.UpdatedParsed != nil {
+		fields.updatedAt = sql.NullTime{Time: item.UpdatedParsed.UTC(), Valid: true}
+	}
+
+	return fields
+}
+
+func itemAuthor(item *gofeed.Item) string {
+	names := []string{}
+	for _, a := range item.Authors {
+		if a != nil && strings.TrimSpace(a.Name) != "" {
+			names = append(names, strings.TrimSpace(a.Name))
+		}
+	}
+
+	if len(names) == 0 && item.Author != nil {
+		return strings.TrimSpace(item.Author.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// feedBaseURL returns the URL relative links in feed resolve against, or nil.
+func feedBaseURL(feed *gofeed.Feed) *url.URL {
+	for _, raw := range []string{feed.Link, feed.FeedLink} {
+		if u, err := url.Parse(raw); err == nil && u.IsAbs() {
+			return u
+		}
+	}
+
+	return nil
+}
+
+func shouldUpdateItem(fields itemFields, existingItem database.Item) bool {
+	return fields.title != existingItem.Title ||
+		fields.link != existingItem.Link ||
+		fields.description != existingItem.Description ||
+		fields.content != existingItem.Content ||
+		fields.author != existingItem.Author ||
+		fields.categories != existingItem.Categories ||
+		(!fields.publishedAt.IsZero() && !fields.publishedAt.Equal(existingItem.PublishedAt)) ||
+		fields.updatedAt.Valid != existingItem.SourceUpdatedAt.Valid ||
+		!fields.updatedAt.Time.Equal(existingItem.SourceUpdatedAt.Time)
 }
 
 func UpdateFeedItems(ctx context.Context, q *database.Queries, feedID int64, feed *gofeed.Feed, logger *slog.Logger) (int, int, error) {
+	feedBase := feedBaseURL(feed)
+
+	subscribers, err := loadRules(ctx, q, feedID, logger)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	var numNewItems int
 	var numUpdatedItems int
 	for _, item := range feed.Items {
-		if strings.HasPrefix(item.Link, "https://www.youtube.com/shorts/") {
-			continue
-		}
-
 		hash := GetItemHash(item)
+		fields := newItemFields(item, feedBase)
 
 		existingItem, existsErr := q.CheckItemExists(ctx, database.CheckItemExistsParams{FeedID: feedID, Hash: hash})
 		if existsErr == nil {
-			if shouldUpdateItem(item, existingItem) {
+			if shouldUpdateItem(fields, existingItem) {
+				publishedAt := fields.publishedAt
+				if publishedAt.IsZero() {
+					publishedAt = existingItem.PublishedAt
+				}
+
 				if err := q.UpdateItem(
 					ctx,
 					database.UpdateItemParams{
-						Title:       item.Title,
-						Link:        item.Link,
-						Description: item.Description,
-						PublishedAt: (*item.PublishedParsed).UTC(), // Store as UTC
-						ID:          existingItem.ID,
+						Title:           fields.title,
+						Link:            fields.link,
+						Description:     fields.description,
+						Content:         fields.content,
+						Author:          fields.author,
+						Categories:      fields.categories,
+						PublishedAt:     publishedAt,
+						SourceUpdatedAt: fields.updatedAt,
+						ID:              existingItem.ID,
 					},
 				); err != nil {
 					return 0, 0, fmt.Errorf("updating item: %w", err)
@@ -75,20 +292,52 @@ func UpdateFeedItems(ctx context.Context, q *database.Queries, feedID int64, fee
 			continue
 
 		} else {
-			if err := q.CreateItem(
+			// Items deleted by the retention policy stay deleted.
+			tombstoned, err := q.CheckItemTombstoneExists(ctx, database.CheckItemTombstoneExistsParams{FeedID: feedID, Hash: hash})
+			if err != nil {
+				return 0, 0, fmt.Errorf("checking item tombstone: %w", err)
+			}
+			if tombstoned != 0 {
+				continue
+			}
+
+			publishedAt := fields.publishedAt
+			if publishedAt.IsZero() {
+				publishedAt = time.Now().UTC()
+			}
+
+			states := newItemStates(subscribers, fields.ruleTarget(item.Categories))
+			if len(states) == 0 {
+				// Every subscriber's rules dropped the item.
+				continue
+			}
+
+			itemID, err := q.CreateItem(
 				ctx,
 				database.CreateItemParams{
-					FeedID:      feedID,
-					Title:       item.Title,
-					Link:        item.Link,
-					Hash:        hash,
-					Description: item.Description,
-					PublishedAt: (*item.PublishedParsed).UTC(), // Store as UTC
+					FeedID:          feedID,
+					Title:           fields.title,
+					Link:            fields.link,
+					Hash:            hash,
+					Description:     fields.description,
+					Content:         fields.content,
+					Author:          fields.author,
+					Categories:      fields.categories,
+					PublishedAt:     publishedAt,
+					SourceUpdatedAt: fields.updatedAt,
 				},
-			); err != nil {
+			)
+			if err != nil {
 				return 0, 0, fmt.Errorf("creating item: %w", err)
 			}
 
+			for _, state := range states {
+				state.ItemID = itemID
+				if err := q.CreateItemState(ctx, state); err != nil {
+					return 0, 0, fmt.Errorf("creating item state: %w", err)
+				}
+			}
+
 			numNewItems++
 		}
 	}
@@ -103,6 +352,16 @@ func UpdateFeedItems(ctx context.Context, q *database.Queries, feedID int64, fee
 		}
 	}
 
+	// Clients link to the site, so like item links only http and https URLs
+	// are kept.
+	var siteURL sql.NullString
+	if strings.TrimSpace(feed.Link) != "" {
+		siteURL.String, siteURL.Valid = sanitizeURL(feed.Link, feedBase, false)
+	}
+	if err := q.UpdateFeedSiteURL(ctx, database.UpdateFeedSiteURLParams{SiteURL: siteURL, ID: feedID}); err != nil {
+		logger.Error("Failed to update feeds.site_url.")
+	}
+
 	if err := q.UpdateFeedLastRefreshedAt(ctx, feedID); err != nil {
 		logger.Error("Failed to update feeds.last_refreshed_at.")
 	}
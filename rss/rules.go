@@ -0,0 +1,227 @@
+package rss
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid rule")
+)
+
+// Rule is a filter rule that is ready to be evaluated against items.
+type Rule struct {
+	database.Rule
+	re *regexp.Regexp
+}
+
+// CompileRule validates r and compiles its pattern.
+func CompileRule(r database.Rule) (Rule, error) {
+	if !slices.Contains(database.AllRuleFieldValues(), r.Field) {
+		return Rule{}, fmt.Errorf("%w: unknown field %q", ErrInvalidRule, r.Field)
+	}
+
+	if !slices.Contains(database.AllRuleActionValues(), r.Action) {
+		return Rule{}, fmt.Errorf("%w: unknown action %q", ErrInvalidRule, r.Action)
+	}
+
+	if r.Action == database.RuleActionTag && strings.TrimSpace(r.Tag) == "" {
+		return Rule{}, fmt.Errorf("%w: tag rules need a tag", ErrInvalidRule)
+	}
+
+	if r.Pattern == "" {
+		return Rule{}, fmt.Errorf("%w: empty pattern", ErrInvalidRule)
+	}
+
+	compiled := Rule{Rule: r}
+
+	switch r.MatchType {
+	case database.RuleMatchContains:
+	case database.RuleMatchRegex:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+		}
+		compiled.re = re
+	default:
+		return Rule{}, fmt.Errorf("%w: unknown match type %q", ErrInvalidRule, r.MatchType)
+	}
+
+	return compiled, nil
+}
+
+// ruleTarget is the part of an item that rules match against.
+type ruleTarget struct {
+	title      string
+	link       string
+	author     string
+	content    string
+	categories []string
+}
+
+func (f itemFields) ruleTarget(categories []string) ruleTarget {
+	return ruleTarget{
+		title:      f.title,
+		link:       f.link,
+		author:     f.author,
+		content:    f.content + "\n" + f.description,
+		categories: categories,
+	}
+}
+
+// MatchesItem reports whether r matches an item that has already been stored.
+func (r Rule) MatchesItem(item database.Item) bool {
+	return r.matches(ruleTarget{
+		title:      item.Title,
+		link:       item.Link,
+		author:     item.Author,
+		content:    item.Content + "\n" + item.Description,
+		categories: item.CategoryNames(),
+	})
+}
+
+func (r Rule) matches(t ruleTarget) bool {
+	switch r.Field {
+	case database.RuleFieldTitle:
+		return r.matchString(t.title)
+	case database.RuleFieldLink:
+		return r.matchString(t.link)
+	case database.RuleFieldAuthor:
+		return r.matchString(t.author)
+	case database.RuleFieldContent:
+		return r.matchString(t.content)
+	case database.RuleFieldCategory:
+		return slices.ContainsFunc(t.categories, r.matchString)
+	default:
+		return false
+	}
+}
+
+// matchString matches s against the rule's pattern. Substring matches ignore
+// case.
+func (r Rule) matchString(s string) bool {
+	if r.re != nil {
+		return r.re.MatchString(s)
+	}
+
+	return strings.Contains(strings.ToLower(s), strings.ToLower(r.Pattern))
+}
+
+// ruleOutcome is the combined effect of every rule matching an item.
+type ruleOutcome struct {
+	drop     bool
+	markRead bool
+	star     bool
+	tags     []string
+}
+
+func evaluateRules(rules []Rule, t ruleTarget) ruleOutcome {
+	var outcome ruleOutcome
+	for _, r := range rules {
+		if !r.matches(t) {
+			continue
+		}
+
+		switch r.Action {
+		case database.RuleActionDrop:
+			outcome.drop = true
+		case database.RuleActionMarkRead:
+			outcome.markRead = true
+		case database.RuleActionStar:
+			outcome.star = true
+		case database.RuleActionTag:
+			if !slices.Contains(outcome.tags, r.Tag) {
+				outcome.tags = append(outcome.tags, r.Tag)
+			}
+		}
+	}
+
+	return outcome
+}
+
+// subscriberRules are the rules that apply to a feed for one of its
+// subscribers.
+type subscriberRules struct {
+	userID sql.NullInt64
+	rules  []Rule
+}
+
+// loadRules returns each subscriber of feedID with their global rules and
+// their rules for the feed. Invalid rules are logged and skipped.
+func loadRules(ctx context.Context, q *database.Queries, feedID int64, logger *slog.Logger) ([]subscriberRules, error) {
+	userIDs, err := q.ListFeedSubscribers(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("listing subscribers: %w", err)
+	}
+
+	dbRules, err := q.ListRulesForFeed(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("listing rules: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(dbRules))
+	for _, r := range dbRules {
+		compiled, err := CompileRule(r)
+		if err != nil {
+			logger.Warn("Skipping invalid rule.", "rule_id", r.ID, "error", err)
+			continue
+		}
+		rules = append(rules, compiled)
+	}
+
+	subscribers := make([]subscriberRules, 0, len(userIDs))
+	for _, userID := range userIDs {
+		sub := subscriberRules{userID: userID}
+		for _, r := range rules {
+			if r.UserID == userID {
+				sub.rules = append(sub.rules, r)
+			}
+		}
+		subscribers = append(subscribers, sub)
+	}
+
+	return subscribers, nil
+}
+
+// newItemStates applies each subscriber's rules to a new item, returning the
+// state it starts with for every subscriber that doesn't drop it. ItemID is
+// left for the caller to fill in.
+func newItemStates(subscribers []subscriberRules, t ruleTarget) []database.CreateItemStateParams {
+	states := make([]database.CreateItemStateParams, 0, len(subscribers))
+	for _, sub := range subscribers {
+		outcome := evaluateRules(sub.rules, t)
+		if outcome.drop {
+			continue
+		}
+
+		status := database.StatusUnread
+		if outcome.markRead {
+			status = database.StatusRead
+		}
+
+		tags := "[]"
+		if len(outcome.tags) > 0 {
+			if b, err := json.Marshal(outcome.tags); err == nil {
+				tags = string(b)
+			}
+		}
+
+		states = append(states, database.CreateItemStateParams{
+			UserID:  sub.userID,
+			Status:  status,
+			Starred: outcome.star,
+			Tags:    tags,
+		})
+	}
+
+	return states
+}
@@ -0,0 +1,237 @@
+package rss
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags maps the elements kept by sanitizeHTML to the attributes they
+// may keep. Elements that aren't listed are unwrapped, keeping their children.
+var allowedTags = map[atom.Atom][]string{
+	atom.A:          {"href"},
+	atom.Abbr:       nil,
+	atom.Audio:      {"src", "controls"},
+	atom.B:          nil,
+	atom.Blockquote: {"cite"},
+	atom.Br:         nil,
+	atom.Caption:    nil,
+	atom.Code:       nil,
+	atom.Dd:         nil,
+	atom.Del:        nil,
+	atom.Details:    nil,
+	atom.Div:        nil,
+	atom.Dl:         nil,
+	atom.Dt:         nil,
+	atom.Em:         nil,
+	atom.Figcaption: nil,
+	atom.Figure:     nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Hr:         nil,
+	atom.I:          nil,
+	atom.Iframe:     {"src", "width", "height", "allowfullscreen"},
+	atom.Img:        {"src", "alt", "width", "height"},
+	atom.Ins:        nil,
+	atom.Kbd:        nil,
+	atom.Li:         nil,
+	atom.Mark:       nil,
+	atom.Ol:         {"start"},
+	atom.P:          nil,
+	atom.Picture:    nil,
+	atom.Pre:        nil,
+	atom.Q:          {"cite"},
+	atom.S:          nil,
+	atom.Small:      nil,
+	atom.Source:     {"src", "type"},
+	atom.Span:       nil,
+	atom.Strong:     nil,
+	atom.Sub:        nil,
+	atom.Summary:    nil,
+	atom.Sup:        nil,
+	atom.Table:      nil,
+	atom.Tbody:      nil,
+	atom.Td:         {"colspan", "rowspan"},
+	atom.Tfoot:      nil,
+	atom.Th:         {"colspan", "rowspan"},
+	atom.Thead:      nil,
+	atom.Time:       {"datetime"},
+	atom.Tr:         nil,
+	atom.U:          nil,
+	atom.Ul:         nil,
+	atom.Video:      {"src", "controls", "poster", "width", "height"},
+}
+
+// globalAttrs may be kept on any allowed element.
+var globalAttrs = []string{"title", "lang", "dir"}
+
+// droppedTags are removed along with everything inside them.
+var droppedTags = []atom.Atom{
+	atom.Applet,
+	atom.Button,
+	atom.Embed,
+	atom.Form,
+	atom.Head,
+	atom.Input,
+	atom.Link,
+	atom.Math,
+	atom.Meta,
+	atom.Noscript,
+	atom.Object,
+	atom.Script,
+	atom.Select,
+	atom.Style,
+	atom.Svg,
+	atom.Template,
+	atom.Textarea,
+	atom.Title,
+}
+
+// urlAttrs are attributes whose values are URLs and are resolved against the
+// base URL.
+var urlAttrs = []string{"href", "src", "cite", "poster"}
+
+// iframeHosts are the hosts embedded iframes may point to.
+var iframeHosts = []string{
+	"www.youtube.com",
+	"youtube.com",
+	"www.youtube-nocookie.com",
+	"youtube-nocookie.com",
+	"player.vimeo.com",
+}
+
+// sanitizeHTML returns raw with everything but an allowlist of elements and
+// attributes removed, so it can be rendered in-app. Relative URLs are
+// resolved against base, which may be nil, and links open in a new tab
+// without access to the opener.
+func sanitizeHTML(raw string, base *url.URL) string {
+	if strings.TrimSpace(raw) == "" {
+		return raw
+	}
+
+	parent := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), parent)
+	if err != nil {
+		return html.EscapeString(raw)
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		for _, clean := range sanitizeNode(n, base) {
+			if err := html.Render(&b, clean); err != nil {
+				return html.EscapeString(raw)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// sanitizeNode returns the sanitized copies of n to put in its place.
+func sanitizeNode(n *html.Node, base *url.URL) []*html.Node {
+	switch n.Type {
+	case html.TextNode:
+		return []*html.Node{{Type: html.TextNode, Data: n.Data}}
+	case html.ElementNode:
+	default:
+		// Comments, doctypes and anything else are dropped.
+		return nil
+	}
+
+	if slices.Contains(droppedTags, n.DataAtom) {
+		return nil
+	}
+
+	children := []*html.Node{}
+	for c := range n.ChildNodes() {
+		children = append(children, sanitizeNode(c, base)...)
+	}
+
+	allowed, ok := allowedTags[n.DataAtom]
+	if !ok {
+		return children
+	}
+
+	clean := &html.Node{Type: html.ElementNode, Data: n.Data, DataAtom: n.DataAtom}
+	for _, a := range n.Attr {
+		if a.Namespace != "" || (!slices.Contains(allowed, a.Key) && !slices.Contains(globalAttrs, a.Key)) {
+			continue
+		}
+
+		if slices.Contains(urlAttrs, a.Key) {
+			u, ok := sanitizeURL(a.Val, base, a.Key == "href")
+			if !ok {
+				continue
+			}
+			a.Val = u
+		}
+
+		clean.Attr = append(clean.Attr, html.Attribute{Key: a.Key, Val: a.Val})
+	}
+
+	switch n.DataAtom {
+	case atom.A:
+		clean.Attr = append(clean.Attr,
+			html.Attribute{Key: "rel", Val: "noopener noreferrer"},
+			html.Attribute{Key: "target", Val: "_blank"},
+		)
+	case atom.Iframe:
+		if !allowedIframe(clean) {
+			return nil
+		}
+	}
+
+	for _, c := range children {
+		clean.AppendChild(c)
+	}
+
+	return []*html.Node{clean}
+}
+
+// sanitizeURL resolves raw against base and reports whether it is safe to
+// keep. Only http and https URLs are allowed, plus mailto for links.
+func sanitizeURL(raw string, base *url.URL, isLink bool) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.String(), true
+	case "mailto":
+		return u.String(), isLink
+	default:
+		// This includes relative URLs that couldn't be resolved, which would
+		// otherwise point at this app.
+		return "", false
+	}
+}
+
+func allowedIframe(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if a.Key != "src" {
+			continue
+		}
+
+		u, err := url.Parse(a.Val)
+		if err != nil {
+			return false
+		}
+
+		return u.Scheme == "https" && slices.Contains(iframeHosts, strings.ToLower(u.Host))
+	}
+
+	return false
+}
@@ -0,0 +1,255 @@
+package rss
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	base, err := url.Parse("https://example.com/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		base *url.URL
+		want string
+	}{
+		{
+			name: "plain text",
+			raw:  "hello <b>world</b>",
+			want: "hello <b>world</b>",
+		},
+		{
+			name: "blank",
+			raw:  "  ",
+			want: "  ",
+		},
+		{
+			name: "script",
+			raw:  `<p>a<script>alert(document.cookie)</script>b</p>`,
+			want: "<p>ab</p>",
+		},
+		{
+			name: "script split by a comment",
+			raw:  `<scr<!-- -->ipt>alert(1)</script>`,
+			want: "ipt&gt;alert(1)",
+		},
+		{
+			name: "style",
+			raw:  `<style>body { display: none }</style><p>text</p>`,
+			want: "<p>text</p>",
+		},
+		{
+			name: "style attribute",
+			raw:  `<p style="position: fixed; inset: 0">text</p>`,
+			want: "<p>text</p>",
+		},
+		{
+			name: "svg with script",
+			raw:  `<svg onload="alert(1)"><script>alert(2)</script></svg>x`,
+			want: "x",
+		},
+		{
+			name: "event handlers",
+			raw:  `<img src="https://example.com/a.png" onerror="alert(1)" ONLOAD="alert(2)"><p onclick="alert(3)">x</p>`,
+			want: `<img src="https://example.com/a.png"/><p>x</p>`,
+		},
+		{
+			name: "unknown element is unwrapped",
+			raw:  `<marquee onstart="alert(1)">x</marquee>`,
+			want: "x",
+		},
+		{
+			name: "form is dropped",
+			raw:  `<form action="https://evil.example/"><input name="password"></form>x`,
+			want: "x",
+		},
+		{
+			name: "javascript link",
+			raw:  `<a href="javascript:alert(1)">x</a>`,
+			want: `<a rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "javascript link with whitespace and case",
+			raw:  `<a href="  JaVaScRiPt:alert(1)">x</a>`,
+			want: `<a rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "javascript link with entities",
+			raw:  `<a href="&#106;avascript:alert(1)">x</a>`,
+			want: `<a rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "data image",
+			raw:  `<img src="data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=">`,
+			want: "<img/>",
+		},
+		{
+			name: "data link",
+			raw:  `<a href="data:text/html,<script>alert(1)</script>">x</a>`,
+			want: `<a rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "vbscript link",
+			raw:  `<a href="vbscript:msgbox(1)">x</a>`,
+			want: `<a rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "mailto link",
+			raw:  `<a href="mailto:me@example.com">x</a>`,
+			want: `<a href="mailto:me@example.com" rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "mailto image",
+			raw:  `<img src="mailto:me@example.com">`,
+			want: "<img/>",
+		},
+		{
+			name: "link rel and target are replaced",
+			raw:  `<a href="https://example.com/" rel="opener" target="_self">x</a>`,
+			want: `<a href="https://example.com/" rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "allowed iframe",
+			raw:  `<iframe src="https://www.youtube.com/embed/abc" width="560" height="315" allowfullscreen srcdoc="<script>alert(1)</script>"></iframe>`,
+			want: `<iframe src="https://www.youtube.com/embed/abc" width="560" height="315" allowfullscreen=""></iframe>`,
+		},
+		{
+			name: "iframe of another host",
+			raw:  `<iframe src="https://evil.example/embed"></iframe>x`,
+			want: "x",
+		},
+		{
+			name: "iframe of an allowed host over http",
+			raw:  `<iframe src="http://www.youtube.com/embed/abc"></iframe>`,
+			want: "",
+		},
+		{
+			name: "iframe with a lookalike host",
+			raw:  `<iframe src="https://www.youtube.com.evil.example/embed/abc"></iframe>`,
+			want: "",
+		},
+		{
+			name: "iframe without src",
+			raw:  `<iframe srcdoc="<script>alert(1)</script>"></iframe>`,
+			want: "",
+		},
+		{
+			name: "javascript iframe",
+			raw:  `<iframe src="javascript:alert(1)"></iframe>`,
+			want: "",
+		},
+		{
+			name: "relative URLs are resolved",
+			raw:  `<a href="/about">a</a><img src="img/b.png"><blockquote cite="../c">c</blockquote>`,
+			base: base,
+			want: `<a href="https://example.com/about" rel="noopener noreferrer" target="_blank">a</a><img src="https://example.com/posts/img/b.png"/><blockquote cite="https://example.com/c">c</blockquote>`,
+		},
+		{
+			name: "protocol-relative URL is resolved",
+			raw:  `<img src="//cdn.example.com/a.png">`,
+			base: base,
+			want: `<img src="https://cdn.example.com/a.png"/>`,
+		},
+		{
+			name: "relative URLs without a base are dropped",
+			raw:  `<a href="/about">a</a><img src="b.png">`,
+			want: `<a rel="noopener noreferrer" target="_blank">a</a><img/>`,
+		},
+		{
+			name: "javascript URL isn't resolved into a safe one",
+			raw:  `<a href="javascript:alert(1)">x</a>`,
+			base: base,
+			want: `<a rel="noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name: "comments are dropped",
+			raw:  `a<!-- <script>alert(1)</script> -->b`,
+			want: "ab",
+		},
+		{
+			name: "text is escaped",
+			raw:  `&lt;script&gt;alert(1)&lt;/script&gt;`,
+			want: "&lt;script&gt;alert(1)&lt;/script&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeHTML(tt.raw, tt.base); got != tt.want {
+				t.Errorf("sanitizeHTML(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeURL(t *testing.T) {
+	base, err := url.Parse("https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		raw    string
+		base   *url.URL
+		isLink bool
+		want   string
+		wantOK bool
+	}{
+		{name: "https", raw: "https://example.com/a", want: "https://example.com/a", wantOK: true},
+		{name: "http", raw: "http://example.com/a", want: "http://example.com/a", wantOK: true},
+		{name: "upper case scheme", raw: "HTTPS://example.com/a", want: "https://example.com/a", wantOK: true},
+		{name: "surrounding whitespace", raw: "  https://example.com/a\n", want: "https://example.com/a", wantOK: true},
+		{name: "relative", raw: "/a", base: base, want: "https://example.com/a", wantOK: true},
+		{name: "relative without base", raw: "/a"},
+		{name: "javascript", raw: "javascript:alert(1)", isLink: true},
+		{name: "javascript with base", raw: "javascript:alert(1)", base: base, isLink: true},
+		{name: "data", raw: "data:text/html,<script>alert(1)</script>", isLink: true},
+		{name: "file", raw: "file:///etc/passwd", isLink: true},
+		{name: "mailto link", raw: "mailto:me@example.com", isLink: true, want: "mailto:me@example.com", wantOK: true},
+		{name: "mailto elsewhere", raw: "mailto:me@example.com", want: "mailto:me@example.com"},
+		{name: "unparseable", raw: "http://[::1", isLink: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := sanitizeURL(tt.raw, tt.base, tt.isLink)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("sanitizeURL(%q) = %q, %t, want %q, %t", tt.raw, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewItemFieldsLink(t *testing.T) {
+	base, err := url.Parse("https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{name: "absolute", link: "https://example.com/posts/1", want: "https://example.com/posts/1"},
+		{name: "relative", link: "/posts/1", want: "https://example.com/posts/1"},
+		{name: "missing", link: "", want: ""},
+		{name: "javascript", link: "javascript:alert(1)", want: ""},
+		{name: "data", link: "data:text/html,<script>alert(1)</script>", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newItemFields(&gofeed.Item{Link: tt.link}, base).link; got != tt.want {
+				t.Errorf("link of %q = %q, want %q", tt.link, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,771 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/cors"
+)
+
+// The JSON API for scripts and other tools, described by openapi.json.
+
+const apiMaxPageSize = 100
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+func (s *Server) apiRoutes(r chi.Router) {
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, NewAPIError(http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))) //nolint:err113
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, NewAPIError(http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed for %s", r.Method, r.URL.Path))) //nolint:err113
+	})
+
+	// Scripts authenticate with tokens rather than cookies, so they can be
+	// allowed from any origin.
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	}))
+
+	r.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(openAPIDocument)
+	})
+
+	r.Group(s.apiAuthRoutes)
+}
+
+func (s *Server) apiAuthRoutes(r chi.Router) {
+	r.Use(s.requireAPIAuth)
+
+	r.Get("/feeds", s.HandleJSON(s.apiListFeeds))
+	r.Post("/feeds", s.HandleFetchJSON(s.apiCreateFeed))
+	r.Post("/feeds/refresh", s.HandleJSON(s.apiRefreshFeeds))
+	r.Get("/feeds/{id:^[0-9]+}", s.HandleJSON(s.apiGetFeed))
+	r.Patch("/feeds/{id:^[0-9]+}", s.HandleFetchJSON(s.apiUpdateFeed))
+	r.Delete("/feeds/{id:^[0-9]+}", s.HandleJSON(s.apiDeleteFeed))
+	r.Post("/feeds/{id:^[0-9]+}/refresh", s.HandleJSON(s.apiRefreshFeed))
+	r.Get("/items", s.HandleJSON(s.apiListItems))
+	r.Get("/items/{id:^[0-9]+}", s.HandleJSON(s.apiGetItem))
+	r.Patch("/items/{id:^[0-9]+}", s.HandleJSON(s.apiUpdateItem))
+	r.Get("/counts", s.HandleJSON(s.apiCounts))
+}
+
+type apiFeed struct {
+	ID                     int64      `json:"id"`
+	Title                  string     `json:"title"`
+	URL                    string     `json:"url"`
+	SiteURL                *string    `json:"site_url"`
+	CategoryID             *int64     `json:"category_id"`
+	Category               *string    `json:"category"`
+	ImageURL               *string    `json:"image_url"`
+	RefreshIntervalMinutes *int64     `json:"refresh_interval_minutes"`
+	FetchFullContent       bool       `json:"fetch_full_content"`
+	RetentionReadDays      *int64     `json:"retention_read_days"`
+	RetentionMaxItems      *int64     `json:"retention_max_items"`
+	LastRefreshedAt        *time.Time `json:"last_refreshed_at"`
+	NextRefreshAt          *time.Time `json:"next_refresh_at"`
+	LastError              *string    `json:"last_error"`
+	ConsecutiveFailures    int64      `json:"consecutive_failures"`
+	CreatedAt              time.Time  `json:"created_at"`
+}
+
+func newAPIFeed(feed database.UserFeed, categoryTitles map[int64]string) apiFeed {
+	f := apiFeed{
+		ID:                     feed.ID,
+		Title:                  feed.Title,
+		URL:                    feed.URL,
+		SiteURL:                nullStringPtr(feed.SiteURL),
+		CategoryID:             nullInt64Ptr(feed.CategoryID),
+		ImageURL:               nullStringPtr(feed.Image),
+		RefreshIntervalMinutes: nullInt64Ptr(feed.RefreshIntervalMinutes),
+		FetchFullContent:       feed.FetchFullContent,
+		RetentionReadDays:      nullInt64Ptr(feed.RetentionReadDays),
+		RetentionMaxItems:      nullInt64Ptr(feed.RetentionMaxItems),
+		LastRefreshedAt:        nullTimePtr(feed.LastRefreshedAt),
+		NextRefreshAt:          nullTimePtr(feed.NextRefreshAt),
+		LastError:              nullStringPtr(feed.LastError),
+		ConsecutiveFailures:    feed.ConsecutiveFailures,
+		CreatedAt:              feed.CreatedAt,
+	}
+
+	if feed.CategoryID.Valid {
+		if title, ok := categoryTitles[feed.CategoryID.Int64]; ok {
+			f.Category = &title
+		}
+	}
+
+	return f
+}
+
+type apiItem struct {
+	ID               int64           `json:"id"`
+	FeedID           int64           `json:"feed_id"`
+	FeedTitle        string          `json:"feed_title"`
+	Title            string          `json:"title"`
+	URL              string          `json:"url"`
+	Author           string          `json:"author"`
+	Description      string          `json:"description"`
+	Content          string          `json:"content"`
+	ExtractedContent string          `json:"extracted_content"`
+	Categories       []string        `json:"categories"`
+	Tags             []string        `json:"tags"`
+	Status           database.Status `json:"status"`
+	Starred          bool            `json:"starred"`
+	PublishedAt      time.Time       `json:"published_at"`
+	SourceUpdatedAt  *time.Time      `json:"source_updated_at"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+func newAPIItem(item database.UserItem, feed database.UserFeed) apiItem {
+	return apiItem{
+		ID:               item.ID,
+		FeedID:           item.FeedID,
+		FeedTitle:        feed.Title,
+		Title:            item.Title,
+		URL:              item.Link,
+		Author:           item.Author,
+		Description:      item.Description,
+		Content:          item.Content,
+		ExtractedContent: item.ExtractedContent,
+		Categories:       item.CategoryNames(),
+		Tags:             item.TagNames(),
+		Status:           item.Status,
+		Starred:          item.Starred,
+		PublishedAt:      item.PublishedAt,
+		SourceUpdatedAt:  nullTimePtr(item.SourceUpdatedAt),
+		CreatedAt:        item.CreatedAt,
+	}
+}
+
+func (s *Server) apiListFeeds(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	feeds, err := q.ListFeeds(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	categoryTitles, err := listCategoryTitles(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]apiFeed, 0, len(feeds))
+	for _, feed := range feeds {
+		resp = append(resp, newAPIFeed(feed, categoryTitles))
+	}
+
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) apiGetFeed(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	feed, err := apiLookupFeed(r, q)
+	if err != nil {
+		return err
+	}
+
+	return writeAPIFeed(ctx, w, http.StatusOK, q, feed)
+}
+
+type apiFeedCreate struct {
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+func (s *Server) apiCreateFeed(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body apiFeedCreate
+	if err := decodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	links, err := discoverFeeds(ctx, body.URL)
+	if err != nil {
+		return err
+	}
+
+	q := database.New(s.db)
+
+	var categoryID sql.NullInt64
+	if title := strings.TrimSpace(body.Category); title != "" {
+		category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{Title: title, UserID: currentUserID(ctx)})
+		if err != nil {
+			return fmt.Errorf("creating category: %w", err)
+		}
+		categoryID = sql.NullInt64{Int64: category.ID, Valid: true}
+	}
+
+	// Unlike the feeds page, don't ask which feed to subscribe to when a page
+	// links to several. The first is usually the main one.
+	feed, err := s.subscribe(ctx, links[0].URL, body.Title, categoryID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/feeds/%d", feed.ID))
+	return writeAPIFeed(ctx, w, http.StatusCreated, q, feed)
+}
+
+// optional is a JSON field that distinguishes being absent from being null.
+type optional[T any] struct {
+	set   bool
+	value *T
+}
+
+func (o *optional[T]) UnmarshalJSON(data []byte) error {
+	o.set = true
+	return json.Unmarshal(data, &o.value) //nolint:wrapcheck
+}
+
+// apiFeedUpdate holds the feed fields to change. Absent fields are left as
+// they are, and null clears the optional ones.
+type apiFeedUpdate struct {
+	Title                  optional[string] `json:"title"`
+	URL                    optional[string] `json:"url"`
+	Category               optional[string] `json:"category"`
+	RefreshIntervalMinutes optional[int64]  `json:"refresh_interval_minutes"`
+	FetchFullContent       optional[bool]   `json:"fetch_full_content"`
+	RetentionReadDays      optional[int64]  `json:"retention_read_days"`
+	RetentionMaxItems      optional[int64]  `json:"retention_max_items"`
+}
+
+func (s *Server) apiUpdateFeed(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body apiFeedUpdate
+	if err := decodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	q := database.New(s.db)
+	feed, err := apiLookupFeed(r, q)
+	if err != nil {
+		return err
+	}
+
+	feedURL := feed.URL
+	sub := database.UpdateSubscriptionParams{
+		Title:                  feed.Title,
+		CategoryID:             feed.CategoryID,
+		RefreshIntervalMinutes: feed.RefreshIntervalMinutes,
+		FetchFullContent:       feed.FetchFullContent,
+		RetentionReadDays:      feed.RetentionReadDays,
+		RetentionMaxItems:      feed.RetentionMaxItems,
+	}
+
+	if body.Title.set {
+		if body.Title.value == nil || strings.TrimSpace(*body.Title.value) == "" {
+			return NewAPIError(http.StatusBadRequest, errors.New("title can't be blank")) //nolint:err113
+		}
+		sub.Title = strings.TrimSpace(*body.Title.value)
+	}
+
+	if body.URL.set {
+		if body.URL.value == nil || strings.TrimSpace(*body.URL.value) == "" {
+			return NewAPIError(http.StatusBadRequest, errors.New("url can't be blank")) //nolint:err113
+		}
+		feedURL = strings.TrimSpace(*body.URL.value)
+	}
+
+	if body.Category.set {
+		sub.CategoryID = sql.NullInt64{}
+		if body.Category.value != nil && strings.TrimSpace(*body.Category.value) != "" {
+			category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{
+				Title:  strings.TrimSpace(*body.Category.value),
+				UserID: currentUserID(ctx),
+			})
+			if err != nil {
+				return fmt.Errorf("creating category: %w", err)
+			}
+			sub.CategoryID = sql.NullInt64{Int64: category.ID, Valid: true}
+		}
+	}
+
+	if body.FetchFullContent.set && body.FetchFullContent.value != nil {
+		sub.FetchFullContent = *body.FetchFullContent.value
+	}
+
+	if sub.RefreshIntervalMinutes, err = optionalInt(body.RefreshIntervalMinutes, "refresh_interval_minutes", sub.RefreshIntervalMinutes, minRefreshIntervalMinutes); err != nil {
+		return err
+	}
+	if sub.RetentionReadDays, err = optionalInt(body.RetentionReadDays, "retention_read_days", sub.RetentionReadDays, 0); err != nil {
+		return err
+	}
+	if sub.RetentionMaxItems, err = optionalInt(body.RetentionMaxItems, "retention_max_items", sub.RetentionMaxItems, 0); err != nil {
+		return err
+	}
+
+	feed, err = s.saveFeed(ctx, feed, feedURL, sub)
+	if err != nil {
+		return err
+	}
+
+	return writeAPIFeed(ctx, w, http.StatusOK, q, feed)
+}
+
+// optionalInt is like [optionalIntField] for JSON fields.
+func optionalInt(o optional[int64], key string, current sql.NullInt64, minValue int) (sql.NullInt64, error) {
+	switch {
+	case !o.set:
+		return current, nil
+	case o.value == nil:
+		return sql.NullInt64{}, nil
+	case *o.value < int64(minValue):
+		return sql.NullInt64{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("%s must be at least %d", key, minValue)) //nolint:err113
+	default:
+		return sql.NullInt64{Int64: *o.value, Valid: true}, nil
+	}
+}
+
+func (s *Server) apiDeleteFeed(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	feed, err := apiLookupFeed(r, q)
+	if err != nil {
+		return err
+	}
+
+	if err := q.DeleteSubscription(ctx, database.DeleteSubscriptionParams{FeedID: feed.ID, UserID: currentUserID(ctx)}); err != nil {
+		return fmt.Errorf("deleting subscription: %w", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) apiRefreshFeed(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	feed, err := apiLookupFeed(r, database.New(conn))
+	if err != nil {
+		return err
+	}
+
+	s.worker.RefreshFeed(feed.ID)
+
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
+
+func (s *Server) apiRefreshFeeds(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	s.worker.RefreshAll()
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
+
+type apiItemPage struct {
+	Items []apiItem `json:"items"`
+	// NextCursor is passed back as the cursor parameter to get the next page.
+	// It is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// apiItemsFilter holds the query parameters of an items listing.
+type apiItemsFilter struct {
+	status     database.Status
+	feedID     int64
+	categoryID int64
+	starred    bool
+	search     string
+	// cursor is a database.ItemCursor, or when searching, the number of
+	// results already returned.
+	cursor string
+	limit  int64
+}
+
+func parseAPIItemsFilter(r *http.Request) (apiItemsFilter, error) {
+	query := r.URL.Query()
+	filter := apiItemsFilter{limit: defaultPageSize}
+
+	if v := query.Get("status"); v != "" {
+		filter.status = database.Status(v)
+		if !slices.Contains(database.AllStatusValues(), filter.status) {
+			return apiItemsFilter{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown status: %s", v)) //nolint:err113
+		}
+	}
+
+	for key, dst := range map[string]*int64{
+		"feed_id":     &filter.feedID,
+		"category_id": &filter.categoryID,
+		"limit":       &filter.limit,
+	} {
+		if v := query.Get(key); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return apiItemsFilter{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("invalid %s: %s", key, v)) //nolint:err113
+			}
+			*dst = n
+		}
+	}
+	filter.limit = min(max(filter.limit, 1), apiMaxPageSize)
+
+	if v := query.Get("starred"); v != "" {
+		starred, err := strconv.ParseBool(v)
+		if err != nil {
+			return apiItemsFilter{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing starred: %w", err))
+		}
+		filter.starred = starred
+	}
+
+	filter.search = strings.TrimSpace(query.Get("q"))
+	filter.cursor = query.Get("cursor")
+
+	return filter, nil
+}
+
+func (s *Server) apiListItems(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	filter, err := parseAPIItemsFilter(r)
+	if err != nil {
+		return err
+	}
+
+	q := database.New(conn)
+
+	var page apiItemPage
+	if filter.search != "" {
+		page, err = apiSearchItems(ctx, q, filter)
+	} else {
+		page, err = apiItems(ctx, q, filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, page)
+}
+
+// apiItems lists items newest first. The cursor is the position of the last
+// item on the previous page, so it stays valid if that item is deleted.
+func apiItems(ctx context.Context, q *database.Queries, filter apiItemsFilter) (apiItemPage, error) {
+	cursor := database.FirstPageCursor(database.ItemOrderNewest)
+	if filter.cursor != "" {
+		var err error
+		if cursor, err = database.ParseItemCursor(filter.cursor); err != nil {
+			return apiItemPage{}, NewAPIError(http.StatusBadRequest, err)
+		}
+	}
+
+	rows, err := q.ListItems(ctx, database.ListItemsParams{
+		UserID:            currentUserID(ctx),
+		HasStatus:         filter.status != database.StatusAny,
+		Status:            filter.status,
+		HasFeedID:         filter.feedID != 0,
+		FeedID:            filter.feedID,
+		HasCategoryID:     filter.categoryID != 0,
+		CategoryID:        filter.categoryID,
+		StarredOnly:       filter.starred,
+		CursorPublishedAt: cursor.Time,
+		CursorID:          cursor.ID,
+		// Fetch one extra item to tell whether there is another page.
+		Limit: filter.limit + 1,
+	})
+	if err != nil {
+		return apiItemPage{}, fmt.Errorf("listing items: %w", err)
+	}
+
+	page := apiItemPage{Items: make([]apiItem, 0, len(rows))}
+	for _, row := range rows[:min(len(rows), int(filter.limit))] {
+		page.Items = append(page.Items, newAPIItem(row.UserItem, row.UserFeed))
+	}
+
+	if len(rows) > int(filter.limit) {
+		page.NextCursor = rows[filter.limit-1].UserItem.Cursor(database.ItemOrderNewest).String()
+	}
+
+	return page, nil
+}
+
+// apiSearchItems lists the items matching filter.search, best match first.
+// The cursor is the number of results already returned.
+func apiSearchItems(ctx context.Context, q *database.Queries, filter apiItemsFilter) (apiItemPage, error) {
+	var offset int64
+	if filter.cursor != "" {
+		var err error
+		if offset, err = strconv.ParseInt(filter.cursor, 10, 64); err != nil || offset < 0 {
+			return apiItemPage{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("invalid cursor: %s", filter.cursor)) //nolint:err113
+		}
+	}
+
+	sq, err := parseSearchQuery(filter.search)
+	if err != nil {
+		return apiItemPage{}, NewAPIError(http.StatusBadRequest, err)
+	}
+	if filter.status != database.StatusAny {
+		sq.status = filter.status
+	}
+
+	matches, err := q.SearchItems(ctx, database.SearchItemsParams{
+		UserID:      currentUserID(ctx),
+		Match:       sq.match,
+		HasStatus:   sq.status != database.StatusAny,
+		Status:      sq.status,
+		FeedTitle:   sq.feedTitle,
+		FeedID:      filter.feedID,
+		CategoryID:  filter.categoryID,
+		StarredOnly: filter.starred,
+		Limit:       filter.limit + 1,
+		Offset:      offset,
+	})
+	if err != nil {
+		return apiItemPage{}, fmt.Errorf("searching items: %w", err)
+	}
+
+	ids := make([]int64, 0, len(matches))
+	for _, m := range matches[:min(len(matches), int(filter.limit))] {
+		ids = append(ids, m.ID)
+	}
+
+	rows, err := q.ListItemsByID(ctx, database.ListItemsByIDParams{Ids: ids, UserID: currentUserID(ctx)})
+	if err != nil {
+		return apiItemPage{}, fmt.Errorf("listing matched items: %w", err)
+	}
+
+	// Restore the rank order of the search results.
+	slices.SortFunc(rows, func(a, b database.ListItemsByIDRow) int {
+		return slices.Index(ids, a.UserItem.ID) - slices.Index(ids, b.UserItem.ID)
+	})
+
+	page := apiItemPage{Items: make([]apiItem, 0, len(rows))}
+	for _, row := range rows {
+		page.Items = append(page.Items, newAPIItem(row.UserItem, row.UserFeed))
+	}
+
+	if len(matches) > int(filter.limit) {
+		page.NextCursor = strconv.FormatInt(offset+filter.limit, 10)
+	}
+
+	return page, nil
+}
+
+func (s *Server) apiGetItem(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	q := database.New(conn)
+	row, err := apiLookupItem(r, q)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newAPIItem(row.UserItem, row.UserFeed))
+}
+
+type apiItemUpdate struct {
+	Status  *database.Status `json:"status"`
+	Starred *bool            `json:"starred"`
+}
+
+func (s *Server) apiUpdateItem(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var body apiItemUpdate
+	if err := decodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	if body.Status != nil && !slices.Contains(database.AllStatusValues(), *body.Status) {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown status: %s", *body.Status)) //nolint:err113
+	}
+
+	q := database.New(conn)
+	row, err := apiLookupItem(r, q)
+	if err != nil {
+		return err
+	}
+
+	if body.Status != nil {
+		if err := q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{Status: *body.Status, ID: row.UserItem.ID, UserID: currentUserID(ctx)}); err != nil {
+			return fmt.Errorf("updating item status: %w", err)
+		}
+		row.UserItem.Status = *body.Status
+	}
+
+	if body.Starred != nil {
+		if err := q.UpdateItemStarred(ctx, database.UpdateItemStarredParams{Starred: *body.Starred, ID: row.UserItem.ID, UserID: currentUserID(ctx)}); err != nil {
+			return fmt.Errorf("updating item starred: %w", err)
+		}
+		row.UserItem.Starred = *body.Starred
+	}
+
+	s.itemStateChanged(ctx)
+
+	log.Add(ctx, row.UserItem.LogValue())
+
+	return writeJSON(w, http.StatusOK, newAPIItem(row.UserItem, row.UserFeed))
+}
+
+type apiCounts struct {
+	Unread     int64              `json:"unread"`
+	Read       int64              `json:"read"`
+	Starred    int64              `json:"starred"`
+	Feeds      []apiFeedCount     `json:"feeds"`
+	Categories []apiCategoryCount `json:"categories"`
+}
+
+type apiFeedCount struct {
+	FeedID int64 `json:"feed_id"`
+	Unread int64 `json:"unread"`
+}
+
+type apiCategoryCount struct {
+	CategoryID int64  `json:"category_id"`
+	Title      string `json:"title"`
+	Unread     int64  `json:"unread"`
+}
+
+func (s *Server) apiCounts(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	userID := currentUserID(ctx)
+	q := database.New(conn)
+
+	var counts apiCounts
+	var err error
+
+	counts.Unread, err = q.CountItems(ctx, database.CountItemsParams{UserID: userID, HasStatus: true, Status: database.StatusUnread})
+	if err != nil {
+		return fmt.Errorf("counting unread items: %w", err)
+	}
+
+	counts.Read, err = q.CountItems(ctx, database.CountItemsParams{UserID: userID, HasStatus: true, Status: database.StatusRead})
+	if err != nil {
+		return fmt.Errorf("counting read items: %w", err)
+	}
+
+	counts.Starred, err = q.CountItems(ctx, database.CountItemsParams{UserID: userID, StarredOnly: true})
+	if err != nil {
+		return fmt.Errorf("counting starred items: %w", err)
+	}
+
+	feeds, err := q.ListFeeds(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	feedCounts, err := q.ListFeedUnreadCounts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("counting unread items per feed: %w", err)
+	}
+
+	unreadByFeed := make(map[int64]int64, len(feedCounts))
+	for _, c := range feedCounts {
+		unreadByFeed[c.FeedID] = c.UnreadCount
+	}
+
+	counts.Feeds = make([]apiFeedCount, 0, len(feeds))
+	for _, feed := range feeds {
+		counts.Feeds = append(counts.Feeds, apiFeedCount{FeedID: feed.ID, Unread: unreadByFeed[feed.ID]})
+	}
+
+	categoryCounts, err := q.ListCategoryUnreadCounts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("counting unread items per category: %w", err)
+	}
+
+	counts.Categories = make([]apiCategoryCount, 0, len(categoryCounts))
+	for _, c := range categoryCounts {
+		counts.Categories = append(counts.Categories, apiCategoryCount{
+			CategoryID: c.Category.ID,
+			Title:      c.Category.Title,
+			Unread:     c.UnreadCount,
+		})
+	}
+
+	return writeJSON(w, http.StatusOK, counts)
+}
+
+func apiLookupFeed(r *http.Request, q *database.Queries) (database.UserFeed, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return database.UserFeed{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing feed ID: %w", err))
+	}
+
+	feed, err := q.GetUserFeed(r.Context(), database.GetUserFeedParams{ID: id, UserID: currentUserID(r.Context())})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.UserFeed{}, NewAPIError(http.StatusNotFound, fmt.Errorf("feed %d not found", id)) //nolint:err113
+		}
+		return database.UserFeed{}, fmt.Errorf("getting feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+func apiLookupItem(r *http.Request, q *database.Queries) (database.GetItemRow, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return database.GetItemRow{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing item ID: %w", err))
+	}
+
+	row, err := q.GetItem(r.Context(), database.GetItemParams{ID: id, UserID: currentUserID(r.Context())})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.GetItemRow{}, NewAPIError(http.StatusNotFound, fmt.Errorf("item %d not found", id)) //nolint:err113
+		}
+		return database.GetItemRow{}, fmt.Errorf("getting item: %w", err)
+	}
+
+	return row, nil
+}
+
+func writeAPIFeed(ctx context.Context, w http.ResponseWriter, status int, q *database.Queries, feed database.UserFeed) error {
+	categoryTitles, err := listCategoryTitles(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, status, newAPIFeed(feed, categoryTitles))
+}
+
+// decodeJSON decodes the request body into v, rejecting unknown fields so
+// typos don't go unnoticed.
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("decoding request body: %w", err))
+	}
+	return nil
+}
+
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+// patchFeed updates one of alice's feeds through the JSON API, returning the
+// response status and the updated feed.
+func (f *testFixture) patchFeed(t *testing.T, feedID int64, body string) (int, apiFeed) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPatch,
+		fmt.Sprintf("%s/api/v1/feeds/%d", f.srv.URL, feedID),
+		strings.NewReader(body),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+aliceToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var feed apiFeed
+	if res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(&feed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return res.StatusCode, feed
+}
+
+func TestAPIUpdateFeedSettingsArePerSubscriber(t *testing.T) {
+	f := newTestFixture(t)
+
+	status, feed := f.patchFeed(t, f.goFeed, `{
+		"refresh_interval_minutes": 60,
+		"fetch_full_content": true,
+		"retention_read_days": 1,
+		"retention_max_items": 5
+	}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if feed.RefreshIntervalMinutes == nil || *feed.RefreshIntervalMinutes != 60 ||
+		!feed.FetchFullContent ||
+		feed.RetentionReadDays == nil || *feed.RetentionReadDays != 1 ||
+		feed.RetentionMaxItems == nil || *feed.RetentionMaxItems != 5 {
+		t.Errorf("alice's settings weren't saved: %+v", feed)
+	}
+
+	bobs, err := f.q.GetUserFeed(t.Context(), database.GetUserFeedParams{ID: f.goFeed, UserID: f.bob})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bobs.RefreshIntervalMinutes.Valid || bobs.FetchFullContent || bobs.RetentionReadDays.Valid || bobs.RetentionMaxItems.Valid {
+		t.Errorf("bob's settings changed with alice's: %+v", bobs)
+	}
+}
+
+func TestAPIUpdateFeedURL(t *testing.T) {
+	f := newTestFixture(t)
+	ctx := t.Context()
+
+	newFeed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Go Mirror</title></channel></rss>`)
+	}))
+	t.Cleanup(newFeed.Close)
+
+	rule, err := f.q.CreateRule(ctx, database.CreateRuleParams{
+		FeedID:    sql.NullInt64{Int64: f.goFeed, Valid: true},
+		Field:     database.RuleFieldTitle,
+		MatchType: database.RuleMatchContains,
+		Pattern:   "generics",
+		Action:    database.RuleActionStar,
+		UserID:    f.alice,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.readAndStar(t, f.goItems[0], f.goItems[1])
+
+	status, feed := f.patchFeed(t, f.goFeed, fmt.Sprintf(`{"url": %q, "retention_read_days": 3}`, newFeed.URL))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if feed.ID == f.goFeed || feed.URL != newFeed.URL {
+		t.Errorf("updated feed = %d %s, want a new feed for %s", feed.ID, feed.URL, newFeed.URL)
+	}
+	if feed.Title != "go" || feed.CategoryID == nil || *feed.CategoryID != f.tech {
+		t.Errorf("updated feed title = %q, category = %v, want alice's title and category", feed.Title, feed.CategoryID)
+	}
+	if feed.RetentionReadDays == nil || *feed.RetentionReadDays != 3 {
+		t.Errorf("updated feed retention read days = %v, want 3", feed.RetentionReadDays)
+	}
+
+	if _, err := f.q.GetUserFeed(ctx, database.GetUserFeedParams{ID: f.goFeed, UserID: f.alice}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("alice is still subscribed to the old feed: %v", err)
+	}
+
+	bobs, err := f.q.GetUserFeed(ctx, database.GetUserFeedParams{ID: f.goFeed, UserID: f.bob})
+	if err != nil {
+		t.Fatalf("bob's subscription to the old feed: %v", err)
+	}
+	if bobs.URL != "https://go.example.com/feed.xml" {
+		t.Errorf("bob's feed URL = %q, want it unchanged", bobs.URL)
+	}
+
+	bobsItems, err := f.q.ListItemsByID(ctx, database.ListItemsByIDParams{UserID: f.bob, Ids: f.goItems})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bobsItems) != len(f.goItems) {
+		t.Errorf("bob has %d of the old feed's items, want %d", len(bobsItems), len(f.goItems))
+	}
+
+	// The new feed starts with copies of the old feed's items, with alice's
+	// state.
+	want := map[string]itemState{
+		"go 0": {read: true},
+		"go 1": {starred: true},
+		"go 2": {},
+	}
+	if got := f.itemStates(t, f.alice, feed.ID); !maps.Equal(got, want) {
+		t.Errorf("alice's items in the new feed = %v, want %v", got, want)
+	}
+
+	rules, err := f.q.ListRules(ctx, f.alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].ID != rule.ID || rules[0].FeedID.Int64 != feed.ID {
+		t.Errorf("alice's rules = %+v, want rule %d moved to feed %d", rules, rule.ID, feed.ID)
+	}
+}
+
+func TestAPIUpdateFeedURLToExistingFeed(t *testing.T) {
+	f := newTestFixture(t)
+	ctx := t.Context()
+
+	// Bob already subscribes to a mirror of the go feed, which shares one of
+	// its items.
+	mirror, err := f.q.CreateFeed(ctx, database.CreateFeedParams{Title: "mirror", URL: "https://mirror.example.com/feed.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hash := range []string{"go-0", "mirror-only"} {
+		if _, err := f.q.CreateItem(ctx, database.CreateItemParams{
+			FeedID:      mirror.ID,
+			Title:       "mirror " + hash,
+			Categories:  "[]",
+			Hash:        hash,
+			PublishedAt: f.oldest,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.q.CreateSubscription(ctx, database.CreateSubscriptionParams{UserID: f.bob, FeedID: mirror.ID, Title: "mirror"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.q.CreateSubscriptionItemStates(ctx, database.CreateSubscriptionItemStatesParams{UserID: f.bob, FeedID: mirror.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	f.readAndStar(t, f.goItems[0], f.goItems[0])
+
+	status, feed := f.patchFeed(t, f.goFeed, `{"url": "https://mirror.example.com/feed.xml"}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if feed.ID != mirror.ID {
+		t.Errorf("updated feed = %d, want the existing feed %d", feed.ID, mirror.ID)
+	}
+
+	// The mirror keeps its own items, and only the shared one takes alice's
+	// state.
+	want := map[string]itemState{
+		"mirror go-0":        {read: true, starred: true},
+		"mirror mirror-only": {},
+	}
+	if got := f.itemStates(t, f.alice, mirror.ID); !maps.Equal(got, want) {
+		t.Errorf("alice's items in the existing feed = %v, want %v", got, want)
+	}
+
+	if got := f.itemStates(t, f.bob, mirror.ID); !maps.Equal(got, map[string]itemState{"mirror go-0": {}, "mirror mirror-only": {}}) {
+		t.Errorf("bob's items in the existing feed = %v, want them unread", got)
+	}
+}
+
+func TestAPIUpdateFeedURLAlreadySubscribed(t *testing.T) {
+	f := newTestFixture(t)
+
+	status, _ := f.patchFeed(t, f.goFeed, `{"url": "https://rust.example.com/feed.xml"}`)
+	if status != http.StatusConflict {
+		t.Errorf("status = %d, want %d", status, http.StatusConflict)
+	}
+
+	if _, err := f.q.GetUserFeed(t.Context(), database.GetUserFeedParams{ID: f.goFeed, UserID: f.alice}); err != nil {
+		t.Errorf("alice's subscription to the old feed: %v", err)
+	}
+}
+
+// readAndStar marks readID as read and starredID as starred for alice.
+func (f *testFixture) readAndStar(t *testing.T, readID, starredID int64) {
+	t.Helper()
+
+	if err := f.q.UpdateItemStatus(t.Context(), database.UpdateItemStatusParams{Status: database.StatusRead, ID: readID, UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.q.UpdateItemStarred(t.Context(), database.UpdateItemStarredParams{Starred: true, ID: starredID, UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type itemState struct {
+	read, starred bool
+}
+
+// itemStates returns the state of each of the user's items in the feed, by
+// title.
+func (f *testFixture) itemStates(t *testing.T, userID, feedID int64) map[string]itemState {
+	t.Helper()
+
+	cursor := database.FirstPageCursor(database.ItemOrderNewest)
+	rows, err := f.q.ListItems(t.Context(), database.ListItemsParams{
+		UserID:            userID,
+		HasFeedID:         true,
+		FeedID:            feedID,
+		CursorPublishedAt: cursor.Time,
+		CursorID:          cursor.ID,
+		Limit:             100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states := make(map[string]itemState, len(rows))
+	for _, row := range rows {
+		states[row.UserItem.Title] = itemState{read: row.UserItem.Status == database.StatusRead, starred: row.UserItem.Starred}
+	}
+	return states
+}
@@ -0,0 +1,464 @@
+package server
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/rss/components"
+	"github.com/ethansaxenian/rss/contextkeys"
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/log"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	sessionCookie     = "session"
+	sessionDuration   = 30 * 24 * time.Hour
+	minPasswordLength = 8
+)
+
+var (
+	errUnauthorized       = errors.New("unauthorized")
+	errInvalidCredentials = errors.New("invalid username or password")
+)
+
+// currentUserID returns the ID of the user the request is authenticated as.
+func currentUserID(ctx context.Context) int64 {
+	return contextkeys.GetUserCtx(ctx).ID
+}
+
+// hashToken returns the hash session and API tokens are stored as, so a
+// leaked database doesn't leak working credentials.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// feverKey returns the API key Fever clients send for token, which is the MD5
+// of "username:token".
+func feverKey(username, token string) string {
+	sum := md5.Sum([]byte(username + ":" + token)) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionUser returns the user signed in with the request's session cookie.
+func (s *Server) sessionUser(r *http.Request) (database.User, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return database.User{}, false
+	}
+
+	row, err := database.New(s.db).GetSessionUser(r.Context(), database.GetSessionUserParams{
+		TokenHash: hashToken(cookie.Value),
+		Now:       time.Now().UTC(),
+	})
+	if err != nil {
+		return database.User{}, false
+	}
+
+	return row.User, true
+}
+
+// tokenUser returns the user an API token belongs to.
+func (s *Server) tokenUser(ctx context.Context, token string) (database.User, bool) {
+	q := database.New(s.db)
+
+	row, err := q.GetAPITokenUser(ctx, hashToken(token))
+	if err != nil {
+		return database.User{}, false
+	}
+
+	if err := q.TouchAPIToken(ctx, hashToken(token)); err != nil {
+		s.log.Warn("Updating API token last used time.", "error", err)
+	}
+
+	return row.User, true
+}
+
+// requireSession sends visitors who aren't signed in to the login page.
+func (s *Server) requireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.sessionUser(r)
+		if !ok {
+			log.Add(r.Context(), slog.GroupAttrs("error", slog.String("message", errUnauthorized.Error())))
+			if r.Header.Get("HX-Request") == "true" {
+				w.Header().Set("HX-Redirect", "/login")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+
+		log.Add(r.Context(), user.LogValue())
+		next.ServeHTTP(w, r.WithContext(contextkeys.WithUserCtx(r.Context(), user)))
+	})
+}
+
+// requireAPIAuth authenticates JSON API requests with a bearer token, or with
+// the session cookie for requests from the web UI. Cookie-authenticated
+// requests from other origins are rejected, since the browser sends the
+// cookie along with them.
+func (s *Server) requireAPIAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user database.User
+		var ok bool
+		if token, isBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); isBearer {
+			user, ok = s.tokenUser(r.Context(), token)
+		} else if user, ok = s.sessionUser(r); ok {
+			if err := s.csrf.Check(r); err != nil {
+				log.Add(r.Context(), slog.GroupAttrs("error", slog.String("message", err.Error())))
+				writeJSONError(w, NewAPIError(http.StatusForbidden, err))
+				return
+			}
+		}
+
+		if !ok {
+			log.Add(r.Context(), slog.GroupAttrs("error", slog.String("message", errUnauthorized.Error())))
+			writeJSONError(w, NewAPIError(http.StatusUnauthorized, errUnauthorized))
+			return
+		}
+
+		log.Add(r.Context(), user.LogValue())
+		next.ServeHTTP(w, r.WithContext(contextkeys.WithUserCtx(r.Context(), user)))
+	})
+}
+
+// canSignUp reports whether new accounts can be created. The first account
+// can always be created.
+func (s *Server) canSignUp(ctx context.Context, q *database.Queries) (bool, error) {
+	if s.signupEnabled {
+		return true, nil
+	}
+
+	count, err := q.CountUsers(ctx)
+	if err != nil {
+		return false, fmt.Errorf("counting users: %w", err)
+	}
+
+	return count == 0, nil
+}
+
+func (s *Server) loginPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if _, ok := s.sessionUser(r); ok {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return nil
+	}
+
+	canSignUp, err := s.canSignUp(ctx, database.New(conn))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.LoginPage(false, canSignUp, "").Render(ctx, w)
+}
+
+func (s *Server) login(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	q := database.New(conn)
+
+	user, err := q.GetUserByUsername(ctx, strings.TrimSpace(r.PostForm.Get("username")))
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(r.PostForm.Get("password")))
+	}
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return fmt.Errorf("checking password: %w", err)
+		}
+
+		log.Add(ctx, slog.GroupAttrs("error", slog.String("message", errInvalidCredentials.Error())))
+
+		canSignUp, err := s.canSignUp(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+		return components.LoginPage(false, canSignUp, "Invalid username or password.").Render(ctx, w)
+	}
+
+	if err := startSession(ctx, q, w, r, user); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+	return nil
+}
+
+func (s *Server) signupPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	canSignUp, err := s.canSignUp(ctx, database.New(conn))
+	if err != nil {
+		return err
+	}
+	if !canSignUp {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.LoginPage(true, true, "").Render(ctx, w)
+}
+
+func (s *Server) signup(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	q := database.New(conn)
+
+	canSignUp, err := s.canSignUp(ctx, q)
+	if err != nil {
+		return err
+	}
+	if !canSignUp {
+		return NewAPIError(http.StatusForbidden, errors.New("sign up is disabled")) //nolint:err113
+	}
+
+	username := strings.TrimSpace(r.PostForm.Get("username"))
+	password := r.PostForm.Get("password")
+
+	var errMessage string
+	switch {
+	case username == "":
+		errMessage = "Choose a username."
+	case len(password) < minPasswordLength:
+		errMessage = fmt.Sprintf("Passwords must be at least %d characters.", minPasswordLength)
+	}
+
+	var user database.User
+	if errMessage == "" {
+		user, err = createUser(ctx, conn, username, password)
+		if database.IsUniqueConstraintErr(err) {
+			errMessage = "That username is taken."
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if errMessage != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return components.LoginPage(true, true, errMessage).Render(ctx, w)
+	}
+
+	log.Add(ctx, user.LogValue())
+
+	if err := startSession(ctx, q, w, r, user); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+	return nil
+}
+
+// createUser creates an account. The first account takes over the feeds,
+// categories and rules created before there were accounts.
+func createUser(ctx context.Context, conn *sql.Conn, username, password string) (database.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return database.User{}, fmt.Errorf("hashing password: %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return database.User{}, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(conn).WithTx(tx)
+
+	count, err := q.CountUsers(ctx)
+	if err != nil {
+		return database.User{}, fmt.Errorf("counting users: %w", err)
+	}
+
+	user, err := q.CreateUser(ctx, database.CreateUserParams{Username: username, PasswordHash: string(hash)})
+	if err != nil {
+		return database.User{}, fmt.Errorf("creating user: %w", err)
+	}
+
+	if count == 0 {
+		if err := q.ClaimUnownedSubscriptions(ctx, user.ID); err != nil {
+			return database.User{}, fmt.Errorf("claiming subscriptions: %w", err)
+		}
+		if err := q.ClaimUnownedItemStates(ctx, user.ID); err != nil {
+			return database.User{}, fmt.Errorf("claiming item states: %w", err)
+		}
+		if err := q.ClaimUnownedCategories(ctx, user.ID); err != nil {
+			return database.User{}, fmt.Errorf("claiming categories: %w", err)
+		}
+		if err := q.ClaimUnownedRules(ctx, user.ID); err != nil {
+			return database.User{}, fmt.Errorf("claiming rules: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.User{}, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return user, nil
+}
+
+// startSession signs user in by setting a new session cookie.
+func startSession(ctx context.Context, q *database.Queries, w http.ResponseWriter, r *http.Request, user database.User) error {
+	now := time.Now().UTC()
+
+	if err := q.DeleteExpiredSessions(ctx, now); err != nil {
+		return fmt.Errorf("deleting expired sessions: %w", err)
+	}
+
+	token := rand.Text()
+	if err := q.CreateSession(ctx, database.CreateSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(sessionDuration),
+	}); err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  now.Add(sessionDuration),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+func (s *Server) logout(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err := database.New(conn).DeleteSession(ctx, hashToken(cookie.Value)); err != nil {
+			return fmt.Errorf("deleting session: %w", err)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+	return nil
+}
+
+func (s *Server) accountPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	return renderAccountPage(conn, w, r, http.StatusOK, "")
+}
+
+func renderAccountPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request, status int, newToken string) error {
+	ctx := r.Context()
+
+	user := contextkeys.GetUserCtx(ctx)
+
+	tokens, err := database.New(conn).ListAPITokens(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("listing API tokens: %w", err)
+	}
+
+	w.WriteHeader(status)
+	return components.AccountPage(user, tokens, newToken).Render(ctx, w)
+}
+
+func (s *Server) updateReadSettings(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	// Unchecked checkboxes aren't submitted.
+	user := contextkeys.GetUserCtx(ctx)
+	user.MarkReadOnOpen = r.PostForm.Has("mark_read_on_open")
+	user.MarkReadOnScroll = r.PostForm.Has("mark_read_on_scroll")
+
+	if err := database.New(conn).UpdateUserReadSettings(ctx, database.UpdateUserReadSettingsParams{
+		MarkReadOnOpen:   user.MarkReadOnOpen,
+		MarkReadOnScroll: user.MarkReadOnScroll,
+		ID:               user.ID,
+	}); err != nil {
+		return fmt.Errorf("updating read settings: %w", err)
+	}
+
+	return renderAccountPage(conn, w, r.WithContext(contextkeys.WithUserCtx(ctx, user)), http.StatusOK, "")
+}
+
+func (s *Server) createAPIToken(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	name := strings.TrimSpace(r.PostForm.Get("name"))
+	if name == "" {
+		return NewAPIError(http.StatusBadRequest, errors.New("token name is required")) //nolint:err113
+	}
+
+	user := contextkeys.GetUserCtx(ctx)
+	token := rand.Text()
+
+	if _, err := database.New(conn).CreateAPIToken(ctx, database.CreateAPITokenParams{
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		FeverKey:  feverKey(user.Username, token),
+	}); err != nil {
+		return fmt.Errorf("creating API token: %w", err)
+	}
+
+	// The token is only ever shown here, since only its hash is stored.
+	return renderAccountPage(conn, w, r, http.StatusCreated, token)
+}
+
+func (s *Server) deleteAPIToken(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing token ID: %w", err))
+	}
+
+	if err := database.New(conn).DeleteAPIToken(ctx, database.DeleteAPITokenParams{
+		ID:     int64(id),
+		UserID: currentUserID(ctx),
+	}); err != nil {
+		return fmt.Errorf("deleting API token: %w", err)
+	}
+
+	w.Header().Set("HX-Location", `{"path": "/account", "target": "#container"}`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
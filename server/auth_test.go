@@ -0,0 +1,319 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+// session signs the user in, returning a session cookie that expires after
+// expiresIn.
+func (f *testFixture) session(t *testing.T, userID int64, expiresIn time.Duration) string {
+	t.Helper()
+
+	token := fmt.Sprintf("session-%d-%d", userID, expiresIn)
+	if err := f.q.CreateSession(t.Context(), database.CreateSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC().Add(expiresIn),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return sessionCookie + "=" + token
+}
+
+// request makes a request without following redirects, returning the
+// response and its body.
+func (f *testFixture) request(t *testing.T, method, path string, header http.Header, body string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), method, f.srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maps.Copy(req.Header, header)
+
+	client := *f.srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp, b
+}
+
+func TestRequireSession(t *testing.T) {
+	f := newTestFixture(t)
+
+	tests := []struct {
+		name         string
+		path         string
+		header       http.Header
+		wantStatus   int
+		wantLocation string
+		wantRedirect string
+	}{
+		{
+			name:         "no session",
+			path:         "/unread",
+			wantStatus:   http.StatusSeeOther,
+			wantLocation: "/login",
+		},
+		{
+			name:         "expired session",
+			path:         "/unread",
+			header:       http.Header{"Cookie": {f.session(t, f.alice, -time.Minute)}},
+			wantStatus:   http.StatusSeeOther,
+			wantLocation: "/login",
+		},
+		{
+			name:         "unknown session",
+			path:         "/unread",
+			header:       http.Header{"Cookie": {sessionCookie + "=unknown"}},
+			wantStatus:   http.StatusSeeOther,
+			wantLocation: "/login",
+		},
+		{
+			name:         "htmx request",
+			path:         "/unread/list",
+			header:       http.Header{"Hx-Request": {"true"}},
+			wantStatus:   http.StatusUnauthorized,
+			wantRedirect: "/login",
+		},
+		{
+			name:       "api token",
+			path:       "/unread",
+			header:     http.Header{"Authorization": {"Bearer " + aliceToken}},
+			wantStatus: http.StatusSeeOther,
+			// The web UI only takes sessions.
+			wantLocation: "/login",
+		},
+		{
+			name:       "api without a token",
+			path:       "/api/v1/feeds",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "api with an unknown token",
+			path:       "/api/v1/feeds",
+			header:     http.Header{"Authorization": {"Bearer unknown"}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "static file",
+			path:       "/static/htmx-ext-sse-2.2.3.js",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signed in",
+			path:       "/unread",
+			header:     http.Header{"Cookie": {f.session(t, f.alice, time.Hour)}},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := f.request(t, http.MethodGet, tt.path, tt.header, "")
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if got := resp.Header.Get("HX-Redirect"); got != tt.wantRedirect {
+				t.Errorf("HX-Redirect = %q, want %q", got, tt.wantRedirect)
+			}
+		})
+	}
+}
+
+func TestCrossOriginRequests(t *testing.T) {
+	f := newTestFixture(t)
+
+	cookie := f.session(t, f.alice, time.Hour)
+	readPath := fmt.Sprintf("/items/%d/status?status=read", f.goItems[0])
+	apiPath := fmt.Sprintf("/api/v1/items/%d", f.goItems[0])
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     http.Header
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "ui from another origin",
+			method:     http.MethodPut,
+			path:       readPath,
+			header:     http.Header{"Cookie": {cookie}, "Origin": {"https://evil.example.com"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "ui from another site",
+			method:     http.MethodPut,
+			path:       readPath,
+			header:     http.Header{"Cookie": {cookie}, "Sec-Fetch-Site": {"cross-site"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "ui login from another origin",
+			method:     http.MethodPost,
+			path:       "/login",
+			header:     http.Header{"Origin": {"https://evil.example.com"}, "Content-Type": {"application/x-www-form-urlencoded"}},
+			body:       "username=alice&password=x",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "api with a session from another origin",
+			method:     http.MethodPatch,
+			path:       apiPath,
+			header:     http.Header{"Cookie": {cookie}, "Origin": {"https://evil.example.com"}, "Content-Type": {"application/json"}},
+			body:       `{"status": "read"}`,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := f.request(t, tt.method, tt.path, tt.header, tt.body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+		})
+	}
+
+	if got := f.itemStates(t, f.alice, f.goFeed)["go 0"]; got.read {
+		t.Errorf("a cross-origin request marked alice's item as read")
+	}
+
+	// The same requests from the app's own origin, or with a token, which
+	// browsers don't send on their own, go through.
+	sameOrigin := http.Header{"Cookie": {cookie}, "Origin": {f.srv.URL}}
+	if resp, body := f.request(t, http.MethodPut, readPath, sameOrigin, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("same-origin status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	token := http.Header{"Authorization": {"Bearer " + aliceToken}, "Origin": {"https://script.example.com"}}
+	if resp, body := f.request(t, http.MethodPatch, apiPath, token, `{"starred": true}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("token status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	if got := f.itemStates(t, f.alice, f.goFeed)["go 0"]; got != (itemState{read: true, starred: true}) {
+		t.Errorf("alice's item = %+v, want it read and starred", got)
+	}
+}
+
+func TestAPITokenSeesOwnFeeds(t *testing.T) {
+	f := newTestFixture(t)
+
+	bob := http.Header{"Authorization": {"Bearer " + bobToken}}
+
+	resp, body := f.request(t, http.MethodGet, "/api/v1/feeds", bob, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	var feeds []apiFeed
+	if err := json.Unmarshal(body, &feeds); err != nil {
+		t.Fatal(err)
+	}
+	if len(feeds) != 1 || feeds[0].ID != f.goFeed {
+		t.Errorf("bob's feeds = %+v, want only feed %d", feeds, f.goFeed)
+	}
+
+	resp, body = f.request(t, http.MethodGet, "/api/v1/items", bob, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	var page apiItemPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, item := range page.Items {
+		ids = append(ids, item.ID)
+	}
+	if !slices.Equal(sorted(ids), f.goItems) {
+		t.Errorf("bob's items = %v, want %v", ids, f.goItems)
+	}
+
+	for _, path := range []string{
+		fmt.Sprintf("/api/v1/feeds/%d", f.rustFeed),
+		fmt.Sprintf("/api/v1/items/%d", f.rustItems[0]),
+	} {
+		if resp, body := f.request(t, http.MethodGet, path, bob, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s as bob = %d, want %d: %s", path, resp.StatusCode, http.StatusNotFound, body)
+		}
+	}
+
+	_, body = f.request(t, http.MethodGet, fmt.Sprintf("/api/v1/items?feed_id=%d", f.rustFeed), bob, "")
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("bob's items in alice's feed = %+v, want none", page.Items)
+	}
+}
+
+func TestItemStatusOtherUser(t *testing.T) {
+	f := newTestFixture(t)
+
+	cookie := http.Header{"Cookie": {f.session(t, f.bob, time.Hour)}}
+	token := http.Header{"Authorization": {"Bearer " + bobToken}, "Content-Type": {"application/json"}}
+
+	// Bob doesn't subscribe to the rust feed, so its items are alice's alone.
+	resp, body := f.request(t, http.MethodPut, fmt.Sprintf("/items/%d/status?status=read", f.rustItems[0]), cookie, "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("ui status = %d, want %d: %s", resp.StatusCode, http.StatusNotFound, body)
+	}
+
+	resp, body = f.request(t, http.MethodPatch, fmt.Sprintf("/api/v1/items/%d", f.rustItems[0]), token, `{"status": "read", "starred": true}`)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("api status = %d, want %d: %s", resp.StatusCode, http.StatusNotFound, body)
+	}
+
+	// Both subscribe to the go feed, but each has their own state for it.
+	resp, body = f.request(t, http.MethodPut, fmt.Sprintf("/items/%d/status?status=read", f.goItems[0]), cookie, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ui status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	resp, body = f.request(t, http.MethodPatch, fmt.Sprintf("/api/v1/items/%d", f.goItems[1]), token, `{"starred": true}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("api status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	for feedID, want := range map[int64]map[string]itemState{
+		f.goFeed:   {"go 0": {}, "go 1": {}, "go 2": {}},
+		f.rustFeed: {"rust 0": {}, "rust 1": {}},
+	} {
+		if got := f.itemStates(t, f.alice, feedID); !maps.Equal(got, want) {
+			t.Errorf("alice's items = %v, want %v", got, want)
+		}
+	}
+
+	if got, want := f.itemStates(t, f.bob, f.goFeed), map[string]itemState{"go 0": {read: true}, "go 1": {starred: true}, "go 2": {}}; !maps.Equal(got, want) {
+		t.Errorf("bob's items = %v, want %v", got, want)
+	}
+}
@@ -1,10 +1,18 @@
 package server
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
 type APIError struct {
-	StatusCode int
-	Message    string
+	StatusCode int    `json:"status"`
+	Message    string `json:"message"`
+}
+
+var errInternalServer = APIError{
+	StatusCode: http.StatusInternalServerError,
+	Message:    "internal server error",
 }
 
 func (e APIError) Error() string {
@@ -17,3 +25,8 @@ func NewAPIError(statusCode int, err error) APIError {
 		Message:    err.Error(),
 	}
 }
+
+// writeJSONError writes apiErr as {"error": {"status": ..., "message": ...}}.
+func writeJSONError(w http.ResponseWriter, apiErr APIError) {
+	_ = writeJSON(w, apiErr.StatusCode, map[string]APIError{"error": apiErr})
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/ethansaxenian/rss/components"
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/log"
+	"github.com/ethansaxenian/rss/worker"
+)
+
+const (
+	// eventDelay gathers bursts of events, like a refresh of every feed, into
+	// one.
+	eventDelay = time.Second
+	// eventKeepAliveInterval keeps idle streams from being closed by proxies.
+	eventKeepAliveInterval = 30 * time.Second
+)
+
+// staticFiles holds a copy of htmx-ext-sse 2.2.3, which connects pages to
+// events. It is sse.js from github.com/bigskysoftware/htmx-extensions at
+// commit 1358232, unchanged, with its 0BSD license. Serving it from the app
+// pins the version pages run. To upgrade it, replace the file and its version
+// and integrity hash in components/layout.templ.
+//
+//go:embed static
+var staticFiles embed.FS
+
+// events streams the signed in user's counts whenever they may have changed,
+// as Server-Sent Events. Each event carries a count rendered as HTML, which
+// pages swap in with sse-swap:
+//
+//   - unread-count, starred-count and history-count are the number of items
+//     in each list.
+//   - feed-counts is the number of unread items in each feed, as out of band
+//     swaps.
+//
+// Unlike other handlers, it doesn't hold a database conn for the life of the
+// request, since streams stay open for as long as a page does.
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rc := http.NewResponseController(w)
+
+	events, unsubscribe := s.worker.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Add(ctx, slog.GroupAttrs("error", slog.String("message", fmt.Sprintf("flushing events: %v", err))))
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	var pending <-chan time.Time
+	for {
+		var message string
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			if pending == nil && s.concernsUser(ctx, event) {
+				pending = time.After(eventDelay)
+			}
+			continue
+		case <-pending:
+			pending = nil
+			var err error
+			if message, err = s.countEvents(ctx); err != nil {
+				s.log.Warn("Rendering count events.", "error", err)
+				continue
+			}
+		case <-keepAlive.C:
+			message = ": keep-alive\n\n"
+		}
+
+		if _, err := fmt.Fprint(w, message); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// countEvents renders the signed in user's counts as the events described by
+// [Server.events].
+func (s *Server) countEvents(ctx context.Context) (string, error) {
+	q := database.New(s.db)
+	userID := currentUserID(ctx)
+
+	unread, err := q.CountItems(ctx, database.CountItemsParams{UserID: userID, HasStatus: true, Status: database.StatusUnread})
+	if err != nil {
+		return "", fmt.Errorf("counting unread items: %w", err)
+	}
+
+	starred, err := q.CountItems(ctx, database.CountItemsParams{UserID: userID, StarredOnly: true})
+	if err != nil {
+		return "", fmt.Errorf("counting starred items: %w", err)
+	}
+
+	read, err := q.CountItems(ctx, database.CountItemsParams{UserID: userID, HasStatus: true, Status: database.StatusRead})
+	if err != nil {
+		return "", fmt.Errorf("counting read items: %w", err)
+	}
+
+	feeds, err := q.ListFeeds(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("listing feeds: %w", err)
+	}
+
+	unreadCounts, err := feedUnreadCounts(ctx, q)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, event := range []struct {
+		name      string
+		component templ.Component
+	}{
+		{"unread-count", components.UnreadCount(unread)},
+		{"starred-count", components.StarredCount(starred)},
+		{"history-count", components.HistoryCount(read)},
+		{"feed-counts", components.FeedUnreadCounts(feeds, unreadCounts)},
+	} {
+		if err := writeEvent(ctx, &b, event.name, event.component); err != nil {
+			return "", err
+		}
+	}
+
+	return b.String(), nil
+}
+
+// writeEvent writes an event named name, with c rendered as its data.
+func writeEvent(ctx context.Context, w io.Writer, name string, c templ.Component) error {
+	var data bytes.Buffer
+	if err := c.Render(ctx, &data); err != nil {
+		return fmt.Errorf("rendering %s: %w", name, err)
+	}
+
+	// Each line of the data needs its own field, and the client joins them
+	// back together.
+	fmt.Fprintf(w, "event: %s\n", name)
+	for line := range strings.Lines(data.String()) {
+		fmt.Fprintf(w, "data: %s\n", strings.TrimSuffix(line, "\n"))
+	}
+	fmt.Fprint(w, "\n")
+
+	return nil
+}
+
+// concernsUser reports whether the event may have changed the signed in
+// user's counts: whether it is about their items, or about a feed they
+// subscribe to.
+func (s *Server) concernsUser(ctx context.Context, event worker.Event) bool {
+	if event.UserID != 0 {
+		return event.UserID == currentUserID(ctx)
+	}
+
+	if event.FeedID != 0 {
+		_, err := database.New(s.db).GetUserFeed(ctx, database.GetUserFeedParams{ID: event.FeedID, UserID: currentUserID(ctx)})
+		return err == nil
+	}
+
+	return false
+}
+
+// itemStateChanged tells the signed in user's open pages that their counts may
+// have changed.
+func (s *Server) itemStateChanged(ctx context.Context) {
+	s.worker.Publish(worker.Event{UserID: currentUserID(ctx)})
+}
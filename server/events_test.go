@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventsSendCounts(t *testing.T) {
+	f := newTestFixture(t)
+
+	cookie := f.session(t, f.alice, time.Hour)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.srv.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Cookie", cookie)
+
+	resp, err := f.srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	f.readAndStar(t, f.goItems[0], f.goItems[1])
+	// Starring through the UI tells the stream the counts changed.
+	starPath := fmt.Sprintf("/items/%d/star?starred=true", f.rustItems[0])
+	if resp, body := f.request(t, http.MethodPut, starPath, http.Header{"Cookie": {cookie}}, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+
+	// Read events until each count has arrived once.
+	events := map[string]string{}
+	var name string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < 4 && scanner.Scan() {
+		line := scanner.Text()
+		if event, ok := strings.CutPrefix(line, "event: "); ok {
+			name = event
+		} else if data, ok := strings.CutPrefix(line, "data: "); ok {
+			events[name] += data
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading events: %v, got %v", err, events)
+	}
+
+	tests := []struct {
+		event string
+		want  string
+	}{
+		{event: "unread-count", want: "<title>(6) RSS</title>(6)"},
+		{event: "starred-count", want: "(2)"},
+		{event: "history-count", want: "(1)"},
+		{event: "feed-counts", want: fmt.Sprintf(`id="feed-unread-%d"`, f.goFeed)},
+		{event: "feed-counts", want: "(2)"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(events[tt.event], tt.want) {
+			t.Errorf("%s event = %q, want it to contain %q", tt.event, events[tt.event], tt.want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/rss/contextkeys"
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/log"
+)
+
+// The Fever API, for older clients like Unread and Reeder classic that don't
+// speak the Google Reader API.
+
+const (
+	feverAPIVersion = 3
+	feverPageSize   = 50
+	// feverKindlingID is the group Fever clients use to mean every feed.
+	feverKindlingID = 0
+)
+
+func (s *Server) fever(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	resp := map[string]any{"api_version": feverAPIVersion, "auth": 0}
+
+	q := database.New(conn)
+
+	// The API key is the MD5 of "username:token", for one of the user's API
+	// tokens.
+	row, err := q.GetFeverKeyUser(ctx, strings.ToLower(r.Form.Get("api_key")))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Add(ctx, slog.GroupAttrs("error", slog.String("message", errUnauthorized.Error())))
+			return writeJSON(w, http.StatusOK, resp)
+		}
+		return fmt.Errorf("getting user: %w", err)
+	}
+	resp["auth"] = 1
+
+	log.Add(ctx, row.User.LogValue())
+	ctx = contextkeys.WithUserCtx(ctx, row.User)
+	r = r.WithContext(ctx)
+
+	feeds, err := q.ListFeeds(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	var lastRefreshed int64
+	for _, f := range feeds {
+		if f.LastRefreshedAt.Valid {
+			lastRefreshed = max(lastRefreshed, f.LastRefreshedAt.Time.Unix())
+		}
+	}
+	resp["last_refreshed_on_time"] = lastRefreshed
+
+	if r.Form.Has("mark") {
+		if err := feverMark(r, q); err != nil {
+			return err
+		}
+		s.itemStateChanged(ctx)
+	}
+
+	if r.Form.Has("groups") || r.Form.Has("feeds") {
+		categories, err := q.ListCategories(ctx, currentUserID(ctx))
+		if err != nil {
+			return fmt.Errorf("listing categories: %w", err)
+		}
+
+		if r.Form.Has("groups") {
+			groups := make([]map[string]any, 0, len(categories))
+			for _, c := range categories {
+				groups = append(groups, map[string]any{"id": c.ID, "title": c.Title})
+			}
+			resp["groups"] = groups
+		}
+
+		if r.Form.Has("feeds") {
+			feverFeeds := make([]map[string]any, 0, len(feeds))
+			for _, f := range feeds {
+				var updated int64
+				if f.LastRefreshedAt.Valid {
+					updated = f.LastRefreshedAt.Time.Unix()
+				}
+				feverFeeds = append(feverFeeds, map[string]any{
+					"id":                   f.ID,
+					"favicon_id":           0,
+					"title":                f.Title,
+					"url":                  f.URL,
+					"site_url":             f.SiteURL.String,
+					"is_spark":             0,
+					"last_updated_on_time": updated,
+				})
+			}
+			resp["feeds"] = feverFeeds
+		}
+
+		resp["feeds_groups"] = feverFeedsGroups(feeds, categories)
+	}
+
+	if r.Form.Has("favicons") {
+		resp["favicons"] = []any{}
+	}
+
+	if r.Form.Has("items") {
+		items, err := feverItems(r, q)
+		if err != nil {
+			return err
+		}
+		resp["items"] = items
+
+		total, err := q.CountItems(ctx, database.CountItemsParams{UserID: currentUserID(ctx)})
+		if err != nil {
+			return fmt.Errorf("counting items: %w", err)
+		}
+		resp["total_items"] = total
+	}
+
+	if r.Form.Has("unread_item_ids") {
+		ids, err := q.ListItemIDs(ctx, database.ListItemIDsParams{
+			UserID:    currentUserID(ctx),
+			HasStatus: true,
+			Status:    database.StatusUnread,
+			Limit:     -1,
+		})
+		if err != nil {
+			return fmt.Errorf("listing unread items: %w", err)
+		}
+		resp["unread_item_ids"] = joinIDs(ids)
+	}
+
+	if r.Form.Has("saved_item_ids") {
+		ids, err := q.ListItemIDs(ctx, database.ListItemIDsParams{UserID: currentUserID(ctx), StarredOnly: true, Limit: -1})
+		if err != nil {
+			return fmt.Errorf("listing saved items: %w", err)
+		}
+		resp["saved_item_ids"] = joinIDs(ids)
+	}
+
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+// feverFeedsGroups lists the feeds in each category.
+func feverFeedsGroups(feeds []database.UserFeed, categories []database.Category) []map[string]any {
+	groups := make([]map[string]any, 0, len(categories))
+	for _, c := range categories {
+		ids := []int64{}
+		for _, f := range feeds {
+			if f.CategoryID.Valid && f.CategoryID.Int64 == c.ID {
+				ids = append(ids, f.ID)
+			}
+		}
+		groups = append(groups, map[string]any{"group_id": c.ID, "feed_ids": joinIDs(ids)})
+	}
+
+	return groups
+}
+
+// feverItems returns up to 50 items: those listed in with_ids, those after
+// since_id, or those before max_id.
+func feverItems(r *http.Request, q *database.Queries) ([]map[string]any, error) {
+	ctx := r.Context()
+
+	var rows []database.ListItemsByIDRow
+	var err error
+
+	switch {
+	case r.Form.Get("with_ids") != "":
+		ids, parseErr := parseIDs(r.Form.Get("with_ids"))
+		if parseErr != nil {
+			return nil, NewAPIError(http.StatusBadRequest, parseErr)
+		}
+		rows, err = q.ListItemsByID(ctx, database.ListItemsByIDParams{
+			Ids:    ids[:min(len(ids), feverPageSize)],
+			UserID: currentUserID(ctx),
+		})
+
+	case r.Form.Get("max_id") != "":
+		maxID, parseErr := strconv.ParseInt(r.Form.Get("max_id"), 10, 64)
+		if parseErr != nil {
+			return nil, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing max_id: %w", parseErr))
+		}
+		var before []database.ListItemsBeforeIDRow
+		before, err = q.ListItemsBeforeID(ctx, database.ListItemsBeforeIDParams{
+			ID:     maxID,
+			UserID: currentUserID(ctx),
+			Limit:  feverPageSize,
+		})
+		for _, row := range before {
+			rows = append(rows, database.ListItemsByIDRow(row))
+		}
+
+	default:
+		sinceID, _ := strconv.ParseInt(r.Form.Get("since_id"), 10, 64)
+		var after []database.ListItemsAfterIDRow
+		after, err = q.ListItemsAfterID(ctx, database.ListItemsAfterIDParams{
+			ID:     sinceID,
+			UserID: currentUserID(ctx),
+			Limit:  feverPageSize,
+		})
+		for _, row := range after {
+			rows = append(rows, database.ListItemsByIDRow(row))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing items: %w", err)
+	}
+
+	items := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		html := row.UserItem.Content
+		if strings.TrimSpace(html) == "" {
+			html = row.UserItem.Description
+		}
+
+		items = append(items, map[string]any{
+			"id":              row.UserItem.ID,
+			"feed_id":         row.UserItem.FeedID,
+			"title":           row.UserItem.Title,
+			"author":          row.UserItem.Author,
+			"html":            html,
+			"url":             row.UserItem.Link,
+			"is_saved":        boolToInt(row.UserItem.Starred),
+			"is_read":         boolToInt(row.UserItem.Status == database.StatusRead),
+			"created_on_time": row.UserItem.PublishedAt.Unix(),
+		})
+	}
+
+	return items, nil
+}
+
+// feverMark handles mark=item, mark=feed and mark=group requests.
+func feverMark(r *http.Request, q *database.Queries) error {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.Form.Get("id"), 10, 64)
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing id: %w", err))
+	}
+
+	as := r.Form.Get("as")
+
+	switch mark := r.Form.Get("mark"); mark {
+	case "item":
+		switch as {
+		case "read", "unread":
+			err = q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{
+				Status: database.Status(as),
+				ID:     id,
+				UserID: currentUserID(ctx),
+			})
+		case "saved", "unsaved":
+			err = q.UpdateItemStarred(ctx, database.UpdateItemStarredParams{
+				Starred: as == "saved",
+				ID:      id,
+				UserID:  currentUserID(ctx),
+			})
+		default:
+			return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown mark as: %s", as)) //nolint:err113
+		}
+		if err != nil {
+			return fmt.Errorf("marking item: %w", err)
+		}
+
+	case "feed", "group":
+		if as != "read" {
+			return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown mark as: %s", as)) //nolint:err113
+		}
+
+		params := database.MarkItemsAsReadParams{UserID: currentUserID(ctx)}
+		switch {
+		case mark == "feed":
+			params.HasFeedID, params.FeedID = true, id
+		case id != feverKindlingID:
+			params.HasCategoryID, params.CategoryID = true, id
+		}
+
+		if before, err := strconv.ParseInt(r.Form.Get("before"), 10, 64); err == nil && before > 0 {
+			params.HasOlderThan, params.OlderThan = true, time.Unix(before, 0).UTC()
+		}
+
+		if err := q.MarkItemsAsRead(ctx, params); err != nil {
+			return fmt.Errorf("marking items as read: %w", err)
+		}
+
+	default:
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown mark: %s", mark)) //nolint:err113
+	}
+
+	return nil
+}
+
+func parseIDs(s string) ([]int64, error) {
+	ids := []int64{}
+	for field := range strings.SplitSeq(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing ID %q: %w", field, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func joinIDs(ids []int64) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(strs, ",")
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,412 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+type feverResponse struct {
+	APIVersion          int   `json:"api_version"`
+	Auth                int   `json:"auth"`
+	LastRefreshedOnTime int64 `json:"last_refreshed_on_time"`
+	Groups              []struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title"`
+	} `json:"groups"`
+	Feeds []struct {
+		ID      int64  `json:"id"`
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		SiteURL string `json:"site_url"`
+	} `json:"feeds"`
+	FeedsGroups []struct {
+		GroupID int64  `json:"group_id"`
+		FeedIDs string `json:"feed_ids"`
+	} `json:"feeds_groups"`
+	Items []struct {
+		ID      int64  `json:"id"`
+		FeedID  int64  `json:"feed_id"`
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		IsRead  int    `json:"is_read"`
+		IsSaved int    `json:"is_saved"`
+	} `json:"items"`
+	TotalItems    int64  `json:"total_items"`
+	UnreadItemIDs string `json:"unread_item_ids"`
+	SavedItemIDs  string `json:"saved_item_ids"`
+}
+
+// fever makes a Fever request with alice's API key. query lists the Fever
+// arguments, like "items&since_id=0".
+func (f *testFixture) fever(t *testing.T, query string, form url.Values) feverResponse {
+	t.Helper()
+	return f.feverWithKey(t, f.feverKey, query, form)
+}
+
+func (f *testFixture) feverWithKey(t *testing.T, key, query string, form url.Values) feverResponse {
+	t.Helper()
+
+	if form == nil {
+		form = url.Values{}
+	}
+	form.Set("api_key", key)
+
+	resp, err := f.srv.Client().PostForm(f.srv.URL+"/fever/?api&"+query, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("POST /fever/?api&%s = %d %s", query, resp.StatusCode, body)
+	}
+
+	var body feverResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	return body
+}
+
+func itemIDs(r feverResponse) []int64 {
+	ids := make([]int64, 0, len(r.Items))
+	for _, item := range r.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func splitIDs(t *testing.T, s string) []int64 {
+	t.Helper()
+
+	ids, err := parseIDs(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func sorted(ids []int64) []int64 {
+	return slices.Sorted(slices.Values(ids))
+}
+
+func TestFeverAuth(t *testing.T) {
+	f := newTestFixture(t)
+
+	tests := []struct {
+		name     string
+		key      string
+		wantAuth int
+	}{
+		{name: "valid key", key: f.feverKey, wantAuth: 1},
+		{name: "upper case key", key: strings.ToUpper(f.feverKey), wantAuth: 1},
+		{name: "wrong key", key: feverKey("alice", "wrong-token")},
+		{name: "another user's name", key: feverKey("bob", "secret-token")},
+		{name: "missing key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.feverWithKey(t, tt.key, "feeds&groups&items&unread_item_ids", nil)
+
+			if got.APIVersion != feverAPIVersion {
+				t.Errorf("api_version = %d, want %d", got.APIVersion, feverAPIVersion)
+			}
+			if got.Auth != tt.wantAuth {
+				t.Errorf("auth = %d, want %d", got.Auth, tt.wantAuth)
+			}
+			if tt.wantAuth == 0 && (got.Feeds != nil || got.Groups != nil || got.Items != nil || got.UnreadItemIDs != "") {
+				t.Errorf("unauthenticated response = %+v, want no data", got)
+			}
+		})
+	}
+}
+
+func TestFeverGroupsAndFeeds(t *testing.T) {
+	f := newTestFixture(t)
+
+	got := f.fever(t, "groups&feeds", nil)
+
+	if len(got.Groups) != 1 || got.Groups[0].ID != f.tech || got.Groups[0].Title != "Tech" {
+		t.Errorf("groups = %+v, want only Tech", got.Groups)
+	}
+
+	wantFeeds := map[int64]string{f.goFeed: "go", f.rustFeed: "rust", f.newsFeed: "news"}
+	if len(got.Feeds) != len(wantFeeds) {
+		t.Errorf("feeds = %+v, want %d feeds", got.Feeds, len(wantFeeds))
+	}
+	for _, feed := range got.Feeds {
+		name, ok := wantFeeds[feed.ID]
+		if !ok {
+			t.Errorf("unexpected feed %+v", feed)
+			continue
+		}
+		if feed.Title != name {
+			t.Errorf("title of feed %d = %q, want %q", feed.ID, feed.Title, name)
+		}
+		if want := "https://" + name + ".example.com/feed.xml"; feed.URL != want {
+			t.Errorf("url of feed %d = %q, want %q", feed.ID, feed.URL, want)
+		}
+		if want := "https://" + name + ".example.com/"; feed.SiteURL != want {
+			t.Errorf("site_url of feed %d = %q, want %q", feed.ID, feed.SiteURL, want)
+		}
+	}
+
+	if len(got.FeedsGroups) != 1 || got.FeedsGroups[0].GroupID != f.tech {
+		t.Fatalf("feeds_groups = %+v, want only Tech", got.FeedsGroups)
+	}
+	if ids, want := splitIDs(t, got.FeedsGroups[0].FeedIDs), sorted([]int64{f.goFeed, f.rustFeed}); !slices.Equal(ids, want) {
+		t.Errorf("feeds in Tech = %v, want %v", ids, want)
+	}
+
+	// feeds_groups is sent with either groups or feeds.
+	if got := f.fever(t, "groups", nil); len(got.FeedsGroups) != 1 || got.Feeds != nil {
+		t.Errorf("groups response = %+v, want groups and feeds_groups only", got)
+	}
+}
+
+func TestFeverItems(t *testing.T) {
+	f := newTestFixture(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int64
+	}{
+		{
+			name:  "first page",
+			query: "items",
+			want:  f.allItems,
+		},
+		{
+			name:  "since_id",
+			query: "items&since_id=" + strconv.FormatInt(f.goItems[2], 10),
+			want:  slices.Concat(f.rustItems, f.newsItems),
+		},
+		{
+			name:  "since_id of the newest item",
+			query: "items&since_id=" + strconv.FormatInt(f.newsItems[1], 10),
+			want:  []int64{},
+		},
+		{
+			name:  "max_id",
+			query: "items&max_id=" + strconv.FormatInt(f.rustItems[0], 10),
+			want:  []int64{f.goItems[2], f.goItems[1], f.goItems[0]},
+		},
+		{
+			name:  "with_ids",
+			query: fmt.Sprintf("items&with_ids=%d,%d", f.goItems[1], f.newsItems[0]),
+			want:  []int64{f.goItems[1], f.newsItems[0]},
+		},
+		{
+			name:  "with_ids takes precedence",
+			query: fmt.Sprintf("items&since_id=0&with_ids=%d", f.rustItems[1]),
+			want:  []int64{f.rustItems[1]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.fever(t, tt.query, nil)
+
+			ids := itemIDs(got)
+			if strings.Contains(tt.query, "with_ids") {
+				ids, tt.want = sorted(ids), sorted(tt.want)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("items = %v, want %v", ids, tt.want)
+			}
+
+			if got.TotalItems != int64(len(f.allItems)) {
+				t.Errorf("total_items = %d, want %d", got.TotalItems, len(f.allItems))
+			}
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		got := f.fever(t, fmt.Sprintf("items&with_ids=%d", f.goItems[0]), nil)
+		if len(got.Items) != 1 {
+			t.Fatalf("items = %+v, want one", got.Items)
+		}
+
+		item := got.Items[0]
+		if item.FeedID != f.goFeed || item.Title != "go 0" || item.URL != "https://go.example.com/0" || item.IsRead != 0 || item.IsSaved != 0 {
+			t.Errorf("item = %+v", item)
+		}
+	})
+
+	t.Run("invalid with_ids", func(t *testing.T) {
+		resp, err := f.srv.Client().PostForm(f.srv.URL+"/fever/?api&items&with_ids=1,x", url.Values{"api_key": {f.feverKey}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestFeverUnreadAndSavedIDs(t *testing.T) {
+	f := newTestFixture(t)
+
+	got := f.fever(t, "unread_item_ids&saved_item_ids", nil)
+	if ids := splitIDs(t, got.UnreadItemIDs); !slices.Equal(ids, sorted(f.allItems)) {
+		t.Errorf("unread_item_ids = %v, want %v", ids, sorted(f.allItems))
+	}
+	if got.SavedItemIDs != "" {
+		t.Errorf("saved_item_ids = %q, want none", got.SavedItemIDs)
+	}
+
+	ctx := t.Context()
+	if err := f.q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{Status: database.StatusRead, ID: f.goItems[0], UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.q.UpdateItemStarred(ctx, database.UpdateItemStarredParams{Starred: true, ID: f.rustItems[1], UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+	// Bob's state doesn't show up in alice's lists.
+	if err := f.q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{Status: database.StatusRead, ID: f.goItems[1], UserID: f.bob}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.q.UpdateItemStarred(ctx, database.UpdateItemStarredParams{Starred: true, ID: f.goItems[2], UserID: f.bob}); err != nil {
+		t.Fatal(err)
+	}
+
+	got = f.fever(t, "unread_item_ids&saved_item_ids", nil)
+	wantUnread := sorted(slices.DeleteFunc(slices.Clone(f.allItems), func(id int64) bool { return id == f.goItems[0] }))
+	if ids := splitIDs(t, got.UnreadItemIDs); !slices.Equal(ids, wantUnread) {
+		t.Errorf("unread_item_ids = %v, want %v", ids, wantUnread)
+	}
+	if ids := splitIDs(t, got.SavedItemIDs); !slices.Equal(ids, []int64{f.rustItems[1]}) {
+		t.Errorf("saved_item_ids = %v, want %v", ids, []int64{f.rustItems[1]})
+	}
+}
+
+func TestFeverMark(t *testing.T) {
+	tests := []struct {
+		name string
+		form func(f *testFixture) url.Values
+		// unread and saved list alice's items that are unread and saved
+		// after marking.
+		unread func(f *testFixture) []int64
+		saved  func(f *testFixture) []int64
+	}{
+		{
+			name: "item as read",
+			form: func(f *testFixture) url.Values {
+				return url.Values{"mark": {"item"}, "as": {"read"}, "id": {strconv.FormatInt(f.goItems[1], 10)}}
+			},
+			unread: func(f *testFixture) []int64 {
+				return slices.Concat(f.goItems[:1], f.goItems[2:], f.rustItems, f.newsItems)
+			},
+		},
+		{
+			name: "item as saved",
+			form: func(f *testFixture) url.Values {
+				return url.Values{"mark": {"item"}, "as": {"saved"}, "id": {strconv.FormatInt(f.newsItems[0], 10)}}
+			},
+			unread: func(f *testFixture) []int64 { return f.allItems },
+			saved:  func(f *testFixture) []int64 { return []int64{f.newsItems[0]} },
+		},
+		{
+			name: "feed as read",
+			form: func(f *testFixture) url.Values {
+				return url.Values{"mark": {"feed"}, "as": {"read"}, "id": {strconv.FormatInt(f.goFeed, 10)}}
+			},
+			unread: func(f *testFixture) []int64 { return slices.Concat(f.rustItems, f.newsItems) },
+		},
+		{
+			name: "feed as read before",
+			form: func(f *testFixture) url.Values {
+				// The first two go items were published before the third.
+				before := f.oldest.Add(90 * time.Minute)
+				return url.Values{"mark": {"feed"}, "as": {"read"}, "id": {strconv.FormatInt(f.goFeed, 10)}, "before": {strconv.FormatInt(before.Unix(), 10)}}
+			},
+			unread: func(f *testFixture) []int64 { return slices.Concat(f.goItems[2:], f.rustItems, f.newsItems) },
+		},
+		{
+			name: "group as read",
+			form: func(f *testFixture) url.Values {
+				return url.Values{"mark": {"group"}, "as": {"read"}, "id": {strconv.FormatInt(f.tech, 10)}}
+			},
+			unread: func(f *testFixture) []int64 { return f.newsItems },
+		},
+		{
+			name: "group as read before",
+			form: func(f *testFixture) url.Values {
+				// Only the go items were published by then.
+				before := f.oldest.Add(150 * time.Minute)
+				return url.Values{"mark": {"group"}, "as": {"read"}, "id": {strconv.FormatInt(f.tech, 10)}, "before": {strconv.FormatInt(before.Unix(), 10)}}
+			},
+			unread: func(f *testFixture) []int64 { return slices.Concat(f.rustItems, f.newsItems) },
+		},
+		{
+			name: "kindling as read",
+			form: func(f *testFixture) url.Values {
+				return url.Values{"mark": {"group"}, "as": {"read"}, "id": {strconv.Itoa(feverKindlingID)}}
+			},
+			unread: func(*testFixture) []int64 { return nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+
+			f.fever(t, "", tt.form(f))
+
+			got := f.fever(t, "unread_item_ids&saved_item_ids", nil)
+			if ids, want := splitIDs(t, got.UnreadItemIDs), sorted(tt.unread(f)); !slices.Equal(ids, want) {
+				t.Errorf("unread_item_ids = %v, want %v", ids, want)
+			}
+
+			var wantSaved []int64
+			if tt.saved != nil {
+				wantSaved = sorted(tt.saved(f))
+			}
+			if ids := splitIDs(t, got.SavedItemIDs); !slices.Equal(ids, wantSaved) && (len(ids) > 0 || len(wantSaved) > 0) {
+				t.Errorf("saved_item_ids = %v, want %v", ids, wantSaved)
+			}
+
+			// Marking only changes alice's state.
+			bobUnread, err := f.q.CountItems(t.Context(), database.CountItemsParams{UserID: f.bob, HasStatus: true, Status: database.StatusUnread})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bobUnread != int64(len(f.goItems)) {
+				t.Errorf("bob has %d unread items, want %d", bobUnread, len(f.goItems))
+			}
+		})
+	}
+
+	t.Run("unread and unsaved undo", func(t *testing.T) {
+		f := newTestFixture(t)
+		id := strconv.FormatInt(f.goItems[0], 10)
+
+		for _, as := range []string{"read", "saved", "unread", "unsaved"} {
+			f.fever(t, "", url.Values{"mark": {"item"}, "as": {as}, "id": {id}})
+		}
+
+		got := f.fever(t, "unread_item_ids&saved_item_ids", nil)
+		if ids := splitIDs(t, got.UnreadItemIDs); !slices.Equal(ids, sorted(f.allItems)) {
+			t.Errorf("unread_item_ids = %v, want %v", ids, sorted(f.allItems))
+		}
+		if got.SavedItemIDs != "" {
+			t.Errorf("saved_item_ids = %q, want none", got.SavedItemIDs)
+		}
+	})
+}
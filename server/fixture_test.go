@@ -0,0 +1,161 @@
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/worker"
+)
+
+// The API tokens of the fixture's users.
+const (
+	aliceToken = "secret-token"
+	bobToken   = "bob-token"
+)
+
+// newTestServer serves the app from a fresh in-memory database.
+func newTestServer(t *testing.T) (*httptest.Server, *database.Queries) {
+	t.Helper()
+
+	db, err := database.Init(t.Context(), fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(t.Context(), 0, db, worker.New(db, worker.RetentionPolicy{}, logger), false, logger)
+
+	srv := httptest.NewServer(s.NewRouter())
+	t.Cleanup(srv.Close)
+
+	return srv, database.New(db)
+}
+
+// testFixture is alice's account: a Tech category with two feeds, an
+// uncategorized feed, and items in each. Bob subscribes to one of the same
+// feeds, so his state must stay apart from hers. Each of them has an API
+// token.
+type testFixture struct {
+	srv *httptest.Server
+	q   *database.Queries
+	// feverKey is alice's Fever API key, for aliceToken.
+	feverKey string
+
+	alice, bob         int64
+	tech               int64
+	goFeed, rustFeed   int64
+	newsFeed           int64
+	goItems, rustItems []int64
+	newsItems          []int64
+	allItems           []int64
+	// oldest is when the first item was published. Each item after it was
+	// published an hour after the one before.
+	oldest time.Time
+}
+
+func newTestFixture(t *testing.T) *testFixture {
+	t.Helper()
+
+	srv, q := newTestServer(t)
+	ctx := t.Context()
+
+	f := &testFixture{srv: srv, q: q}
+
+	createUser := func(username string) int64 {
+		user, err := q.CreateUser(ctx, database.CreateUserParams{Username: username, PasswordHash: "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return user.ID
+	}
+	f.alice = createUser("alice")
+	f.bob = createUser("bob")
+
+	createToken := func(userID int64, username, token string) string {
+		key := feverKey(username, token)
+		if _, err := q.CreateAPIToken(ctx, database.CreateAPITokenParams{
+			UserID:    userID,
+			Name:      "reader",
+			TokenHash: hashToken(token),
+			FeverKey:  key,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	f.feverKey = createToken(f.alice, "alice", aliceToken)
+	createToken(f.bob, "bob", bobToken)
+
+	category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{Title: "Tech", UserID: f.alice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.tech = category.ID
+
+	f.oldest = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	published := f.oldest
+
+	createFeed := func(name string, categoryID sql.NullInt64, numItems int) (int64, []int64) {
+		feed, err := q.CreateFeed(ctx, database.CreateFeedParams{Title: name, URL: "https://" + name + ".example.com/feed.xml"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := q.UpdateFeedSiteURL(ctx, database.UpdateFeedSiteURLParams{
+			SiteURL: sql.NullString{String: "https://" + name + ".example.com/", Valid: true},
+			ID:      feed.ID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []int64
+		for i := range numItems {
+			id, err := q.CreateItem(ctx, database.CreateItemParams{
+				FeedID:      feed.ID,
+				Title:       fmt.Sprintf("%s %d", name, i),
+				Link:        fmt.Sprintf("https://%s.example.com/%d", name, i),
+				Categories:  "[]",
+				Hash:        fmt.Sprintf("%s-%d", name, i),
+				PublishedAt: published,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+			published = published.Add(time.Hour)
+		}
+
+		if err := q.CreateSubscription(ctx, database.CreateSubscriptionParams{UserID: f.alice, FeedID: feed.ID, Title: name, CategoryID: categoryID}); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.CreateSubscriptionItemStates(ctx, database.CreateSubscriptionItemStatesParams{UserID: f.alice, FeedID: feed.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		return feed.ID, ids
+	}
+
+	inTech := sql.NullInt64{Int64: f.tech, Valid: true}
+	f.goFeed, f.goItems = createFeed("go", inTech, 3)
+	f.rustFeed, f.rustItems = createFeed("rust", inTech, 2)
+	f.newsFeed, f.newsItems = createFeed("news", sql.NullInt64{}, 2)
+	f.allItems = slices.Concat(f.goItems, f.rustItems, f.newsItems)
+
+	if err := q.CreateSubscription(ctx, database.CreateSubscriptionParams{UserID: f.bob, FeedID: f.goFeed, Title: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.CreateSubscriptionItemStates(ctx, database.CreateSubscriptionItemStatesParams{UserID: f.bob, FeedID: f.goFeed}); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
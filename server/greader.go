@@ -0,0 +1,921 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/rss/contextkeys"
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/log"
+	"github.com/ethansaxenian/rss/rss"
+	"github.com/go-chi/chi/v5"
+)
+
+// The Google Reader API, as spoken by mobile clients like Reeder,
+// NetNewsWire and FeedMe. Only the subset those clients use is implemented.
+
+const (
+	greaderReadingList = "user/-/state/com.google/reading-list"
+	greaderRead        = "user/-/state/com.google/read"
+	greaderKeptUnread  = "user/-/state/com.google/kept-unread"
+	greaderStarred     = "user/-/state/com.google/starred"
+	greaderLabelPrefix = "user/-/label/"
+	greaderFeedPrefix  = "feed/"
+	greaderItemPrefix  = "tag:google.com,2005:reader/item/"
+
+	greaderDefaultCount = 20
+	greaderMaxCount     = 10000
+	greaderMaxUnread    = 1000
+
+	// dbTimeLayout is how times are stored in the database.
+	dbTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+)
+
+// greaderToken returns the token a client sent in its Authorization header.
+func greaderToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+}
+
+// greaderLogin handles ClientLogin. The password is one of the user's API
+// tokens, which is handed back as the auth token.
+func (s *Server) greaderLogin(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	token := r.Form.Get("Passwd")
+
+	user, ok := s.tokenUser(ctx, token)
+	if !ok || !strings.EqualFold(user.Username, r.Form.Get("Email")) {
+		log.Add(ctx, slog.GroupAttrs("error", slog.String("message", errInvalidCredentials.Error())))
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := io.WriteString(w, "Error=BadAuthentication\n")
+		return err //nolint:wrapcheck
+	}
+
+	log.Add(ctx, user.LogValue())
+
+	if r.Form.Get("output") == "json" {
+		return writeJSON(w, http.StatusOK, map[string]string{"SID": token, "LSID": token, "Auth": token})
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err := fmt.Fprintf(w, "SID=%s\nLSID=%s\nAuth=%s\n", token, token, token)
+	return err //nolint:wrapcheck
+}
+
+// greaderAuth rejects requests without a valid ClientLogin token.
+func (s *Server) greaderAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.tokenUser(r.Context(), greaderToken(r))
+		if !ok {
+			log.Add(r.Context(), slog.GroupAttrs("error", slog.String("message", errUnauthorized.Error())))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		log.Add(r.Context(), user.LogValue())
+		next.ServeHTTP(w, r.WithContext(contextkeys.WithUserCtx(r.Context(), user)))
+	})
+}
+
+func (s *Server) greaderRoutes(r chi.Router) {
+	r.Use(s.greaderAuth)
+
+	r.Get("/token", s.Handle(s.greaderEditToken))
+	r.Get("/user-info", s.Handle(s.greaderUserInfo))
+	r.Get("/subscription/list", s.Handle(s.greaderSubscriptionList))
+	r.Post("/subscription/edit", s.HandleFetch(s.greaderSubscriptionEdit))
+	r.Post("/subscription/quickadd", s.HandleFetch(s.greaderQuickAdd))
+	r.Get("/tag/list", s.Handle(s.greaderTagList))
+	r.Get("/unread-count", s.Handle(s.greaderUnreadCount))
+	r.Get("/stream/items/ids", s.Handle(s.greaderStreamItemIDs))
+	r.Get("/stream/items/contents", s.Handle(s.greaderStreamItemContents))
+	r.Post("/stream/items/contents", s.Handle(s.greaderStreamItemContents))
+	r.Get("/stream/contents", s.Handle(s.greaderStreamContents))
+	r.Get("/stream/contents/*", s.Handle(s.greaderStreamContents))
+	r.Post("/edit-tag", s.Handle(s.greaderEditTag))
+	r.Post("/mark-all-as-read", s.Handle(s.greaderMarkAllAsRead))
+}
+
+func (s *Server) greaderEditToken(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err := io.WriteString(w, greaderToken(r))
+	return err //nolint:wrapcheck
+}
+
+func (s *Server) greaderUserInfo(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	user := contextkeys.GetUserCtx(r.Context())
+	id := strconv.FormatInt(user.ID, 10)
+
+	return writeJSON(w, http.StatusOK, map[string]string{
+		"userId":        id,
+		"userName":      user.Username,
+		"userProfileId": id,
+		"userEmail":     user.Username,
+	})
+}
+
+type greaderCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type greaderSubscription struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Categories []greaderCategory `json:"categories"`
+	URL        string            `json:"url"`
+	HTMLURL    string            `json:"htmlUrl"`
+	IconURL    string            `json:"iconUrl"`
+}
+
+func (s *Server) greaderSubscriptionList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	feeds, err := q.ListFeeds(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	categoryTitles, err := listCategoryTitles(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	subs := make([]greaderSubscription, 0, len(feeds))
+	for _, f := range feeds {
+		sub := greaderSubscription{
+			ID:         greaderFeedID(f.ID),
+			Title:      f.Title,
+			Categories: []greaderCategory{},
+			URL:        f.URL,
+			HTMLURL:    f.SiteURL.String,
+			IconURL:    f.Image.String,
+		}
+		if title, ok := categoryTitles[f.CategoryID.Int64]; ok && f.CategoryID.Valid {
+			sub.Categories = append(sub.Categories, greaderCategory{ID: greaderLabelPrefix + title, Label: title})
+		}
+		subs = append(subs, sub)
+	}
+
+	return writeJSON(w, http.StatusOK, map[string]any{"subscriptions": subs})
+}
+
+func (s *Server) greaderSubscriptionEdit(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	// Subscribing may fetch the feed, so this doesn't hold a conn.
+	q := database.New(s.db)
+
+	for _, streamID := range r.Form["s"] {
+		streamID = normalizeGReaderStreamID(streamID)
+
+		switch action := r.Form.Get("ac"); action {
+		case "subscribe":
+			feedURL := strings.TrimPrefix(streamID, greaderFeedPrefix)
+			if _, err := s.greaderSubscribe(ctx, q, feedURL, r.Form.Get("t"), r.Form.Get("a")); err != nil {
+				return err
+			}
+
+		case "unsubscribe":
+			feed, err := greaderLookupFeed(ctx, q, streamID)
+			if err != nil {
+				return err
+			}
+			if err := q.DeleteSubscription(ctx, database.DeleteSubscriptionParams{FeedID: feed.ID, UserID: currentUserID(ctx)}); err != nil {
+				return fmt.Errorf("deleting subscription: %w", err)
+			}
+
+		case "edit":
+			feed, err := greaderLookupFeed(ctx, q, streamID)
+			if err != nil {
+				return err
+			}
+			if err := greaderEditFeed(ctx, q, feed, r.Form.Get("t"), r.Form.Get("a"), r.Form.Get("r")); err != nil {
+				return err
+			}
+
+		default:
+			return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown action: %s", action)) //nolint:err113
+		}
+	}
+
+	return writeGReaderOK(w)
+}
+
+func (s *Server) greaderQuickAdd(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	query := strings.TrimPrefix(r.Form.Get("quickadd"), greaderFeedPrefix)
+
+	discoverCtx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+
+	links, err := rss.DiscoverFeeds(discoverCtx, query)
+	if err != nil {
+		if errors.Is(err, rss.ErrInvalidURL) || errors.Is(err, rss.ErrNoFeedsFound) {
+			return writeJSON(w, http.StatusOK, map[string]any{"numResults": 0, "query": query})
+		}
+		return NewAPIError(http.StatusBadGateway, fmt.Errorf("discovering feeds: %w", err))
+	}
+
+	feed, err := s.greaderSubscribe(ctx, database.New(s.db), links[0].URL, "", "")
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, map[string]any{
+		"numResults": 1,
+		"query":      query,
+		"streamId":   greaderFeedID(feed.ID),
+		"streamName": feed.Title,
+	})
+}
+
+// greaderSubscribe subscribes to feedURL, returning the existing feed if
+// there already is one.
+func (s *Server) greaderSubscribe(ctx context.Context, q *database.Queries, feedURL, title, label string) (database.UserFeed, error) {
+	feed, err := q.GetUserFeedByURL(ctx, database.GetUserFeedByURLParams{URL: feedURL, UserID: currentUserID(ctx)})
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.UserFeed{}, fmt.Errorf("getting feed: %w", err)
+	}
+
+	var categoryID sql.NullInt64
+	if name, ok := strings.CutPrefix(normalizeGReaderStreamID(label), greaderLabelPrefix); ok && name != "" {
+		category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{Title: name, UserID: currentUserID(ctx)})
+		if err != nil {
+			return database.UserFeed{}, fmt.Errorf("creating category: %w", err)
+		}
+		categoryID = sql.NullInt64{Int64: category.ID, Valid: true}
+	}
+
+	return s.subscribe(ctx, feedURL, title, categoryID)
+}
+
+// greaderEditFeed renames feed if title is set, and moves it into the
+// addLabel category or out of the removeLabel one.
+func greaderEditFeed(ctx context.Context, q *database.Queries, feed database.UserFeed, title, addLabel, removeLabel string) error {
+	params := database.UpdateSubscriptionParams{
+		Title:                  feed.Title,
+		CategoryID:             feed.CategoryID,
+		RefreshIntervalMinutes: feed.RefreshIntervalMinutes,
+		FetchFullContent:       feed.FetchFullContent,
+		RetentionReadDays:      feed.RetentionReadDays,
+		RetentionMaxItems:      feed.RetentionMaxItems,
+		FeedID:                 feed.ID,
+		UserID:                 currentUserID(ctx),
+	}
+
+	if title = strings.TrimSpace(title); title != "" {
+		params.Title = title
+	}
+
+	if name, ok := strings.CutPrefix(normalizeGReaderStreamID(removeLabel), greaderLabelPrefix); ok && name != "" {
+		params.CategoryID = sql.NullInt64{}
+	}
+
+	if name, ok := strings.CutPrefix(normalizeGReaderStreamID(addLabel), greaderLabelPrefix); ok && name != "" {
+		category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{Title: name, UserID: currentUserID(ctx)})
+		if err != nil {
+			return fmt.Errorf("creating category: %w", err)
+		}
+		params.CategoryID = sql.NullInt64{Int64: category.ID, Valid: true}
+	}
+
+	if err := q.UpdateSubscription(ctx, params); err != nil {
+		return fmt.Errorf("updating subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) greaderTagList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	categories, err := database.New(conn).ListCategories(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing categories: %w", err)
+	}
+
+	tags := []map[string]string{{"id": greaderStarred}}
+	for _, c := range categories {
+		tags = append(tags, map[string]string{"id": greaderLabelPrefix + c.Title, "type": "folder"})
+	}
+
+	return writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+type greaderUnreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int64  `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+}
+
+func (s *Server) greaderUnreadCount(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	counts, err := q.ListFeedUnreadCounts(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("counting unread items: %w", err)
+	}
+
+	feeds, err := q.ListFeeds(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	categoryTitles, err := listCategoryTitles(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	feedCategories := make(map[int64]string, len(feeds))
+	for _, f := range feeds {
+		if title, ok := categoryTitles[f.CategoryID.Int64]; ok && f.CategoryID.Valid {
+			feedCategories[f.ID] = title
+		}
+	}
+
+	total := greaderUnreadCount{ID: greaderReadingList}
+	labels := map[string]*greaderUnreadCount{}
+	unreadCounts := []greaderUnreadCount{}
+
+	for _, c := range counts {
+		newest := parseDBTime(c.NewestPublishedAt)
+		usec := strconv.FormatInt(newest.UnixMicro(), 10)
+
+		unreadCounts = append(unreadCounts, greaderUnreadCount{ID: greaderFeedID(c.FeedID), Count: c.UnreadCount, NewestItemTimestampUsec: usec})
+
+		total.Count += c.UnreadCount
+		total.NewestItemTimestampUsec = maxUsec(total.NewestItemTimestampUsec, usec)
+
+		if title, ok := feedCategories[c.FeedID]; ok {
+			label, ok := labels[title]
+			if !ok {
+				label = &greaderUnreadCount{ID: greaderLabelPrefix + title}
+				labels[title] = label
+			}
+			label.Count += c.UnreadCount
+			label.NewestItemTimestampUsec = maxUsec(label.NewestItemTimestampUsec, usec)
+		}
+	}
+
+	for _, label := range labels {
+		unreadCounts = append(unreadCounts, *label)
+	}
+	unreadCounts = append(unreadCounts, total)
+
+	return writeJSON(w, http.StatusOK, map[string]any{"max": greaderMaxUnread, "unreadcounts": unreadCounts})
+}
+
+// greaderStream is a parsed stream query.
+type greaderStream struct {
+	params      database.ListItemIDsParams
+	oldestFirst bool
+}
+
+// parseGReaderStream parses the stream ID and the exclude/include targets,
+// count, continuation, time bounds and ordering of a stream request.
+func parseGReaderStream(ctx context.Context, q *database.Queries, streamID string, form map[string][]string) (greaderStream, error) {
+	get := func(key string) string {
+		if values := form[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	var stream greaderStream
+	p := &stream.params
+
+	if err := applyGReaderStreamID(ctx, q, normalizeGReaderStreamID(streamID), p); err != nil {
+		return greaderStream{}, err
+	}
+
+	for _, target := range form["xt"] {
+		if normalizeGReaderStreamID(target) == greaderRead {
+			p.HasStatus, p.Status = true, database.StatusUnread
+		}
+	}
+
+	for _, target := range form["it"] {
+		switch normalizeGReaderStreamID(target) {
+		case greaderRead:
+			p.HasStatus, p.Status = true, database.StatusRead
+		case greaderStarred:
+			p.StarredOnly = true
+		}
+	}
+
+	p.Limit = greaderDefaultCount
+	if n, err := strconv.Atoi(get("n")); err == nil && n > 0 {
+		p.Limit = int64(min(n, greaderMaxCount))
+	}
+
+	if offset, err := strconv.Atoi(get("c")); err == nil && offset > 0 {
+		p.Offset = int64(offset)
+	}
+
+	// ot is the start time, so it excludes items older than it, and nt is the
+	// stop time, which excludes items newer than it.
+	if ot, err := strconv.ParseInt(get("ot"), 10, 64); err == nil && ot > 0 {
+		p.HasNewerThan, p.NewerThan = true, time.Unix(ot, 0).UTC()
+	}
+
+	if nt, err := strconv.ParseInt(get("nt"), 10, 64); err == nil && nt > 0 {
+		p.HasOlderThan, p.OlderThan = true, time.Unix(nt, 0).UTC()
+	}
+
+	stream.oldestFirst = get("r") == "o"
+
+	return stream, nil
+}
+
+// applyGReaderStreamID narrows p to the feed, label or state streamID refers
+// to.
+func applyGReaderStreamID(ctx context.Context, q *database.Queries, streamID string, p *database.ListItemIDsParams) error {
+	p.UserID = currentUserID(ctx)
+
+	switch {
+	case streamID == "", streamID == greaderReadingList:
+	case streamID == greaderStarred:
+		p.StarredOnly = true
+	case streamID == greaderRead:
+		p.HasStatus, p.Status = true, database.StatusRead
+	case strings.HasPrefix(streamID, greaderLabelPrefix):
+		category, err := q.GetCategoryByTitle(ctx, database.GetCategoryByTitleParams{
+			Title:  strings.TrimPrefix(streamID, greaderLabelPrefix),
+			UserID: currentUserID(ctx),
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewAPIError(http.StatusNotFound, fmt.Errorf("label not found: %s", streamID)) //nolint:err113
+			}
+			return fmt.Errorf("getting category: %w", err)
+		}
+		p.HasCategoryID, p.CategoryID = true, category.ID
+	case strings.HasPrefix(streamID, greaderFeedPrefix):
+		feed, err := greaderLookupFeed(ctx, q, streamID)
+		if err != nil {
+			return err
+		}
+		p.HasFeedID, p.FeedID = true, feed.ID
+	default:
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown stream: %s", streamID)) //nolint:err113
+	}
+
+	return nil
+}
+
+func (stream greaderStream) itemIDs(ctx context.Context, q *database.Queries) ([]int64, error) {
+	var ids []int64
+	var err error
+	if stream.oldestFirst {
+		ids, err = q.ListItemIDsOldestFirst(ctx, database.ListItemIDsOldestFirstParams(stream.params))
+	} else {
+		ids, err = q.ListItemIDs(ctx, stream.params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing item IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// continuation returns the continuation token for the next page, or "" if
+// this is the last one.
+func (stream greaderStream) continuation(numItems int) string {
+	if int64(numItems) < stream.params.Limit {
+		return ""
+	}
+	return strconv.FormatInt(stream.params.Offset+int64(numItems), 10)
+}
+
+func (s *Server) greaderStreamItemIDs(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	q := database.New(conn)
+	stream, err := parseGReaderStream(ctx, q, r.Form.Get("s"), r.Form)
+	if err != nil {
+		return err
+	}
+
+	ids, err := stream.itemIDs(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	refs := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, map[string]any{"id": strconv.FormatInt(id, 10), "directStreamIds": []string{}})
+	}
+
+	resp := map[string]any{"itemRefs": refs}
+	if c := stream.continuation(len(ids)); c != "" {
+		resp["continuation"] = c
+	}
+
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) greaderStreamContents(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	streamID := chi.URLParam(r, "*")
+	if unescaped, err := url.PathUnescape(streamID); err == nil {
+		streamID = unescaped
+	}
+	if streamID == "" {
+		streamID = r.Form.Get("s")
+	}
+
+	q := database.New(conn)
+	stream, err := parseGReaderStream(ctx, q, streamID, r.Form)
+	if err != nil {
+		return err
+	}
+
+	ids, err := stream.itemIDs(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	items, err := greaderItems(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+
+	resp := map[string]any{
+		"id":      normalizeGReaderStreamID(streamID),
+		"updated": time.Now().Unix(),
+		"items":   items,
+	}
+	if c := stream.continuation(len(ids)); c != "" {
+		resp["continuation"] = c
+	}
+
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) greaderStreamItemContents(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	ids, err := parseGReaderItemIDs(r.Form["i"])
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	items, err := greaderItems(ctx, database.New(conn), ids)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, map[string]any{
+		"id":      greaderReadingList,
+		"updated": time.Now().Unix(),
+		"items":   items,
+	})
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type greaderOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type greaderItem struct {
+	ID            string         `json:"id"`
+	CrawlTimeMsec string         `json:"crawlTimeMsec"`
+	TimestampUsec string         `json:"timestampUsec"`
+	Published     int64          `json:"published"`
+	Updated       int64          `json:"updated"`
+	Title         string         `json:"title"`
+	Author        string         `json:"author,omitempty"`
+	Canonical     []greaderLink  `json:"canonical"`
+	Alternate     []greaderLink  `json:"alternate"`
+	Summary       greaderContent `json:"summary"`
+	Categories    []string       `json:"categories"`
+	Origin        greaderOrigin  `json:"origin"`
+}
+
+// greaderItems loads the items with the given IDs, in the same order.
+func greaderItems(ctx context.Context, q *database.Queries, ids []int64) ([]greaderItem, error) {
+	rows, err := q.ListItemsByID(ctx, database.ListItemsByIDParams{Ids: ids, UserID: currentUserID(ctx)})
+	if err != nil {
+		return nil, fmt.Errorf("listing items: %w", err)
+	}
+
+	categoryTitles, err := listCategoryTitles(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]database.ListItemsByIDRow, len(rows))
+	for _, row := range rows {
+		byID[row.UserItem.ID] = row
+	}
+
+	items := make([]greaderItem, 0, len(rows))
+	for _, id := range ids {
+		row, ok := byID[id]
+		if !ok {
+			continue
+		}
+		items = append(items, newGReaderItem(row.UserItem, row.UserFeed, categoryTitles))
+	}
+
+	return items, nil
+}
+
+func newGReaderItem(item database.UserItem, feed database.UserFeed, categoryTitles map[int64]string) greaderItem {
+	updated := item.PublishedAt
+	if item.SourceUpdatedAt.Valid {
+		updated = item.SourceUpdatedAt.Time
+	}
+
+	content := item.Content
+	if strings.TrimSpace(content) == "" {
+		content = item.Description
+	}
+
+	categories := []string{greaderReadingList}
+	if item.Status == database.StatusRead {
+		categories = append(categories, greaderRead)
+	}
+	if item.Starred {
+		categories = append(categories, greaderStarred)
+	}
+	if title, ok := categoryTitles[feed.CategoryID.Int64]; ok && feed.CategoryID.Valid {
+		categories = append(categories, greaderLabelPrefix+title)
+	}
+
+	return greaderItem{
+		ID:            fmt.Sprintf("%s%016x", greaderItemPrefix, uint64(item.ID)), //nolint:gosec
+		CrawlTimeMsec: strconv.FormatInt(item.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: strconv.FormatInt(item.PublishedAt.UnixMicro(), 10),
+		Published:     item.PublishedAt.Unix(),
+		Updated:       updated.Unix(),
+		Title:         item.Title,
+		Author:        item.Author,
+		Canonical:     []greaderLink{{Href: item.Link}},
+		Alternate:     []greaderLink{{Href: item.Link, Type: "text/html"}},
+		Summary:       greaderContent{Direction: "ltr", Content: content},
+		Categories:    categories,
+		Origin: greaderOrigin{
+			StreamID: greaderFeedID(feed.ID),
+			Title:    feed.Title,
+			HTMLURL:  feed.SiteURL.String,
+		},
+	}
+}
+
+func (s *Server) greaderEditTag(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	ids, err := parseGReaderItemIDs(r.Form["i"])
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	var status database.Status
+	var starred *bool
+	for _, tag := range r.Form["a"] {
+		switch normalizeGReaderStreamID(tag) {
+		case greaderRead:
+			status = database.StatusRead
+		case greaderKeptUnread:
+			status = database.StatusUnread
+		case greaderStarred:
+			star := true
+			starred = &star
+		}
+	}
+	for _, tag := range r.Form["r"] {
+		switch normalizeGReaderStreamID(tag) {
+		case greaderRead:
+			status = database.StatusUnread
+		case greaderStarred:
+			star := false
+			starred = &star
+		}
+	}
+
+	q := database.New(conn)
+	for _, id := range ids {
+		if status != database.StatusAny {
+			if err := q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{Status: status, ID: id, UserID: currentUserID(ctx)}); err != nil {
+				return fmt.Errorf("updating item status: %w", err)
+			}
+		}
+		if starred != nil {
+			if err := q.UpdateItemStarred(ctx, database.UpdateItemStarredParams{Starred: *starred, ID: id, UserID: currentUserID(ctx)}); err != nil {
+				return fmt.Errorf("updating item starred: %w", err)
+			}
+		}
+	}
+
+	s.itemStateChanged(ctx)
+
+	return writeGReaderOK(w)
+}
+
+func (s *Server) greaderMarkAllAsRead(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	q := database.New(conn)
+
+	var stream database.ListItemIDsParams
+	if err := applyGReaderStreamID(ctx, q, normalizeGReaderStreamID(r.Form.Get("s")), &stream); err != nil {
+		return err
+	}
+
+	params := database.MarkItemsAsReadParams{
+		UserID:        stream.UserID,
+		HasFeedID:     stream.HasFeedID,
+		FeedID:        stream.FeedID,
+		HasCategoryID: stream.HasCategoryID,
+		CategoryID:    stream.CategoryID,
+		StarredOnly:   stream.StarredOnly,
+	}
+
+	if ts, err := strconv.ParseInt(r.Form.Get("ts"), 10, 64); err == nil && ts > 0 {
+		params.HasOlderThan, params.OlderThan = true, time.UnixMicro(ts).UTC()
+	}
+
+	if err := q.MarkItemsAsRead(ctx, params); err != nil {
+		return fmt.Errorf("marking items as read: %w", err)
+	}
+
+	s.itemStateChanged(ctx)
+
+	return writeGReaderOK(w)
+}
+
+// greaderLookupFeed returns the feed a feed/<id> or feed/<url> stream ID
+// refers to.
+func greaderLookupFeed(ctx context.Context, q *database.Queries, streamID string) (database.UserFeed, error) {
+	ref := strings.TrimPrefix(streamID, greaderFeedPrefix)
+
+	var feed database.UserFeed
+	var err error
+	if id, parseErr := strconv.ParseInt(ref, 10, 64); parseErr == nil {
+		feed, err = q.GetUserFeed(ctx, database.GetUserFeedParams{ID: id, UserID: currentUserID(ctx)})
+	} else {
+		feed, err = q.GetUserFeedByURL(ctx, database.GetUserFeedByURLParams{URL: ref, UserID: currentUserID(ctx)})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.UserFeed{}, NewAPIError(http.StatusNotFound, fmt.Errorf("feed not found: %s", streamID)) //nolint:err113
+		}
+		return database.UserFeed{}, fmt.Errorf("getting feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// parseGReaderItemIDs parses item IDs, which clients send either in the long
+// tag:google.com form with a hex ID or as plain decimal numbers.
+func parseGReaderItemIDs(values []string) ([]int64, error) {
+	ids := make([]int64, 0, len(values))
+	for _, v := range values {
+		if hexID, ok := strings.CutPrefix(v, greaderItemPrefix); ok {
+			id, err := strconv.ParseUint(hexID, 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing item ID %q: %w", v, err)
+			}
+			ids = append(ids, int64(id)) //nolint:gosec
+			continue
+		}
+
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing item ID %q: %w", v, err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// normalizeGReaderStreamID replaces the user ID in user/<id>/... stream IDs
+// with "-".
+func normalizeGReaderStreamID(streamID string) string {
+	rest, ok := strings.CutPrefix(streamID, "user/")
+	if !ok {
+		return streamID
+	}
+
+	if _, after, ok := strings.Cut(rest, "/"); ok {
+		return "user/-/" + after
+	}
+
+	return streamID
+}
+
+func greaderFeedID(id int64) string {
+	return greaderFeedPrefix + strconv.FormatInt(id, 10)
+}
+
+func listCategoryTitles(ctx context.Context, q *database.Queries) (map[int64]string, error) {
+	categories, err := q.ListCategories(ctx, currentUserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("listing categories: %w", err)
+	}
+
+	titles := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		titles[c.ID] = c.Title
+	}
+
+	return titles, nil
+}
+
+// parseDBTime parses a time read from the database as text, returning the
+// zero time if it can't be parsed.
+func parseDBTime(s string) time.Time {
+	t, err := time.Parse(dbTimeLayout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func maxUsec(a, b string) string {
+	x, _ := strconv.ParseInt(a, 10, 64)
+	y, _ := strconv.ParseInt(b, 10, 64)
+	return strconv.FormatInt(max(x, y), 10)
+}
+
+func writeGReaderOK(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err := io.WriteString(w, "OK")
+	return err //nolint:wrapcheck
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	return nil
+}
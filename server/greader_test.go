@@ -0,0 +1,247 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+// greader makes a Google Reader API request as alice, with form as the query
+// of a GET or the body of a POST, and returns the response body.
+func (f *testFixture) greader(t *testing.T, method, path string, form url.Values) []byte {
+	t.Helper()
+
+	target := f.srv.URL + "/reader/api/0" + path
+	var body io.Reader
+	if method == http.MethodGet {
+		target += "?" + form.Encode()
+	} else {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), method, target, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "GoogleLogin auth="+aliceToken)
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := f.srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s %s = %d %s", method, path, resp.StatusCode, b)
+	}
+
+	return b
+}
+
+func TestGReaderSubscriptionEditKeepsSettings(t *testing.T) {
+	f := newTestFixture(t)
+	ctx := t.Context()
+
+	settings := database.UpdateSubscriptionParams{
+		Title:                  "go",
+		CategoryID:             sql.NullInt64{Int64: f.tech, Valid: true},
+		RefreshIntervalMinutes: sql.NullInt64{Int64: 60, Valid: true},
+		FetchFullContent:       true,
+		RetentionReadDays:      sql.NullInt64{Int64: 7, Valid: true},
+		RetentionMaxItems:      sql.NullInt64{Int64: 100, Valid: true},
+		FeedID:                 f.goFeed,
+		UserID:                 f.alice,
+	}
+	if err := f.q.UpdateSubscription(ctx, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	f.greader(t, http.MethodPost, "/subscription/edit", url.Values{
+		"ac": {"edit"},
+		"s":  {greaderFeedID(f.goFeed)},
+		"t":  {"Golang"},
+		"a":  {greaderLabelPrefix + "Languages"},
+	})
+
+	feed, err := f.q.GetUserFeed(ctx, database.GetUserFeedParams{ID: f.goFeed, UserID: f.alice})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Title != "Golang" || feed.CategoryID == settings.CategoryID {
+		t.Errorf("title = %q, category = %v, want the new title and category", feed.Title, feed.CategoryID)
+	}
+	if feed.RefreshIntervalMinutes != settings.RefreshIntervalMinutes ||
+		feed.FetchFullContent != settings.FetchFullContent ||
+		feed.RetentionReadDays != settings.RetentionReadDays ||
+		feed.RetentionMaxItems != settings.RetentionMaxItems {
+		t.Errorf("settings after an edit = %v, %t, %v, %v, want %v, %t, %v, %v",
+			feed.RefreshIntervalMinutes, feed.FetchFullContent, feed.RetentionReadDays, feed.RetentionMaxItems,
+			settings.RefreshIntervalMinutes, settings.FetchFullContent, settings.RetentionReadDays, settings.RetentionMaxItems,
+		)
+	}
+}
+
+func TestGReaderStreamItemIDs(t *testing.T) {
+	f := newTestFixture(t)
+
+	// hour returns the Unix time of the item published hours after the
+	// oldest one.
+	hour := func(hours int) string {
+		return strconv.FormatInt(f.oldest.Add(time.Duration(hours)*time.Hour).Unix(), 10)
+	}
+
+	tests := []struct {
+		name string
+		form url.Values
+		want []int64
+	}{
+		{
+			name: "reading list",
+			form: url.Values{"s": {greaderReadingList}},
+			want: reversed(f.allItems),
+		},
+		{
+			name: "start time",
+			form: url.Values{"s": {greaderReadingList}, "ot": {hour(4)}},
+			want: reversed(f.newsItems),
+		},
+		{
+			name: "stop time",
+			form: url.Values{"s": {greaderReadingList}, "nt": {hour(2)}},
+			want: []int64{f.goItems[1], f.goItems[0]},
+		},
+		{
+			name: "start and stop time",
+			form: url.Values{"s": {greaderReadingList}, "ot": {hour(1)}, "nt": {hour(4)}},
+			want: []int64{f.rustItems[0], f.goItems[2]},
+		},
+		{
+			name: "feed oldest first",
+			form: url.Values{"s": {greaderFeedID(f.goFeed)}, "r": {"o"}},
+			want: f.goItems,
+		},
+		{
+			name: "label",
+			form: url.Values{"s": {greaderLabelPrefix + "Tech"}, "n": {"2"}, "c": {"1"}},
+			want: []int64{f.rustItems[0], f.goItems[2]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp struct {
+				ItemRefs []struct {
+					ID string `json:"id"`
+				} `json:"itemRefs"`
+			}
+			if err := json.Unmarshal(f.greader(t, http.MethodGet, "/stream/items/ids", tt.form), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]int64, 0, len(resp.ItemRefs))
+			for _, ref := range resp.ItemRefs {
+				id, err := strconv.ParseInt(ref.ID, 10, 64)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, id)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("item IDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGReaderStreamContents(t *testing.T) {
+	f := newTestFixture(t)
+
+	// An incremental sync of the go feed since its first item was published.
+	form := url.Values{"ot": {strconv.FormatInt(f.oldest.Unix(), 10)}, "r": {"o"}}
+	body := f.greader(t, http.MethodGet, "/stream/contents/"+url.PathEscape(greaderFeedID(f.goFeed)), form)
+
+	var resp struct {
+		ID    string `json:"id"`
+		Items []struct {
+			ID     string `json:"id"`
+			Title  string `json:"title"`
+			Origin struct {
+				StreamID string `json:"streamId"`
+			} `json:"origin"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.ID != greaderFeedID(f.goFeed) {
+		t.Errorf("stream ID = %q, want %q", resp.ID, greaderFeedID(f.goFeed))
+	}
+
+	var titles []string
+	for _, item := range resp.Items {
+		titles = append(titles, item.Title)
+		if item.Origin.StreamID != greaderFeedID(f.goFeed) {
+			t.Errorf("item %s origin = %q, want %q", item.ID, item.Origin.StreamID, greaderFeedID(f.goFeed))
+		}
+	}
+	if want := []string{"go 1", "go 2"}; !slices.Equal(titles, want) {
+		t.Errorf("items = %v, want %v", titles, want)
+	}
+}
+
+func TestGReaderEditTag(t *testing.T) {
+	f := newTestFixture(t)
+
+	itemID := func(id int64) string {
+		return fmt.Sprintf("%s%016x", greaderItemPrefix, id)
+	}
+
+	f.greader(t, http.MethodPost, "/edit-tag", url.Values{
+		"i": {itemID(f.goItems[0]), strconv.FormatInt(f.goItems[1], 10)},
+		"a": {greaderRead, "user/1/state/com.google/starred"},
+	})
+	f.greader(t, http.MethodPost, "/edit-tag", url.Values{
+		"i": {itemID(f.goItems[1])},
+		"r": {greaderRead},
+	})
+
+	want := map[string]itemState{
+		"go 0": {read: true, starred: true},
+		"go 1": {starred: true},
+		"go 2": {},
+	}
+	if got := f.itemStates(t, f.alice, f.goFeed); !maps.Equal(got, want) {
+		t.Errorf("alice's items = %v, want %v", got, want)
+	}
+
+	if got := f.itemStates(t, f.bob, f.goFeed); !maps.Equal(got, map[string]itemState{"go 0": {}, "go 1": {}, "go 2": {}}) {
+		t.Errorf("bob's items = %v, want them unchanged", got)
+	}
+}
+
+// reversed returns the IDs newest first, the order streams list them in.
+func reversed(ids []int64) []int64 {
+	ids = slices.Clone(ids)
+	slices.Reverse(ids)
+	return ids
+}
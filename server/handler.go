@@ -14,25 +14,57 @@ type APIFunc func(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error
 
 // Handle wraps an [APIFunc] into an [http.HandlerFunc].
 func (s *Server) Handle(h APIFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+	return s.handle(h, func(w http.ResponseWriter, apiErr APIError) {
+		http.Error(w, apiErr.Error(), apiErr.StatusCode)
+	})
+}
+
+// HandleJSON is like [Server.Handle], but writes errors as JSON.
+func (s *Server) HandleJSON(h APIFunc) http.HandlerFunc {
+	return s.handle(h, writeJSONError)
+}
 
-		conn, err := s.db.Conn(ctx)
+func (s *Server) handle(h APIFunc, writeError func(w http.ResponseWriter, apiErr APIError)) http.HandlerFunc {
+	return s.handleErrors(func(w http.ResponseWriter, r *http.Request) error {
+		conn, err := s.db.Conn(r.Context())
 		if err != nil {
-			log.Add(ctx, slog.GroupAttrs("error", slog.String("message", fmt.Sprintf("opening database conn: %v", err))))
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
+			return fmt.Errorf("opening database conn: %w", err)
 		}
 		defer conn.Close()
 
-		if err := h(conn, w, r); err != nil {
-			log.Add(ctx, slog.GroupAttrs("error", slog.String("message", err.Error())))
+		return h(conn, w, r)
+	}, writeError)
+}
+
+// FetchFunc is a handler that fetches from other sites before it uses the
+// database.
+type FetchFunc func(w http.ResponseWriter, r *http.Request) error
+
+// HandleFetch wraps a [FetchFunc] into an [http.HandlerFunc]. Unlike
+// [Server.Handle], it doesn't open a conn for the handler, which uses s.db
+// instead, so that a slow site doesn't hold a conn from the pool while it
+// responds.
+func (s *Server) HandleFetch(h FetchFunc) http.HandlerFunc {
+	return s.handleErrors(h, func(w http.ResponseWriter, apiErr APIError) {
+		http.Error(w, apiErr.Error(), apiErr.StatusCode)
+	})
+}
+
+// HandleFetchJSON is like [Server.HandleFetch], but writes errors as JSON.
+func (s *Server) HandleFetchJSON(h FetchFunc) http.HandlerFunc {
+	return s.handleErrors(h, writeJSONError)
+}
+
+func (s *Server) handleErrors(h FetchFunc, writeError func(w http.ResponseWriter, apiErr APIError)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			log.Add(r.Context(), slog.GroupAttrs("error", slog.String("message", err.Error())))
 
 			var apiErr APIError
 			if errors.As(err, &apiErr) {
-				http.Error(w, err.Error(), apiErr.StatusCode)
+				writeError(w, apiErr)
 			} else {
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				writeError(w, errInternalServer)
 			}
 		}
 	}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+const (
+	itemListCookie         = "item_list"
+	itemListCookieDuration = 365 * 24 * time.Hour
+)
+
+// itemListOptionKeys are the query parameters of itemListOptions.
+var itemListOptionKeys = []string{"sort", "period", "from", "to", "author"}
+
+// itemListOptions are the order and filters picked for an item list.
+type itemListOptions struct {
+	order database.ItemOrder
+	// period is "today" or "week". It takes precedence over from and to.
+	period string
+	// from and to are inclusive dates, as YYYY-MM-DD in the server's time
+	// zone.
+	from   string
+	to     string
+	author string
+}
+
+func parseItemListOptions(query url.Values) (itemListOptions, error) {
+	opts := itemListOptions{
+		order:  database.ItemOrder(query.Get("sort")),
+		period: query.Get("period"),
+		from:   query.Get("from"),
+		to:     query.Get("to"),
+		author: strings.TrimSpace(query.Get("author")),
+	}
+
+	if opts.order == "" {
+		opts.order = database.ItemOrderNewest
+	}
+	if !slices.Contains(database.AllItemOrderValues(), opts.order) {
+		return itemListOptions{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown sort: %s", opts.order)) //nolint:err113
+	}
+
+	if !slices.Contains([]string{"", "today", "week"}, opts.period) {
+		return itemListOptions{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown period: %s", opts.period)) //nolint:err113
+	}
+
+	for _, date := range []string{opts.from, opts.to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return itemListOptions{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing date: %w", err))
+		}
+	}
+
+	return opts, nil
+}
+
+// values returns the options that aren't the defaults, as query parameters.
+func (o itemListOptions) values() url.Values {
+	values := url.Values{}
+	if o.order != database.ItemOrderNewest {
+		values.Set("sort", string(o.order))
+	}
+	for key, value := range map[string]string{
+		"period": o.period,
+		"from":   o.from,
+		"to":     o.to,
+		"author": o.author,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	return values
+}
+
+// publishedRange returns the range of publish times the options select, as
+// [after, before). A zero time leaves that end open.
+func (o itemListOptions) publishedRange(now time.Time) (after, before time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch o.period {
+	case "today":
+		return today.UTC(), time.Time{}
+	case "week":
+		// Weeks start on Monday.
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7).UTC(), time.Time{}
+	}
+
+	if from, err := time.ParseInLocation(time.DateOnly, o.from, now.Location()); err == nil {
+		after = from.UTC()
+	}
+	if to, err := time.ParseInLocation(time.DateOnly, o.to, now.Location()); err == nil {
+		before = to.AddDate(0, 0, 1).UTC()
+	}
+
+	return after, before
+}
+
+// itemListOptionsFor returns the options for the item list requested by r.
+// Options picked on the first page of a list are remembered for that list, in
+// a cookie scoped to its path, and used when the list is loaded without any.
+// Later pages carry the options of the first one.
+func itemListOptionsFor(w http.ResponseWriter, r *http.Request) (itemListOptions, error) {
+	query := r.URL.Query()
+
+	switch {
+	case query.Has("cursor"):
+		return parseItemListOptions(query)
+
+	case slices.ContainsFunc(itemListOptionKeys, query.Has):
+		opts, err := parseItemListOptions(query)
+		if err != nil {
+			return itemListOptions{}, err
+		}
+		rememberItemListOptions(w, r, opts)
+		return opts, nil
+
+	default:
+		cookie, err := r.Cookie(itemListCookie)
+		if err != nil {
+			return parseItemListOptions(query)
+		}
+		remembered, err := url.ParseQuery(cookie.Value)
+		if err != nil {
+			return parseItemListOptions(query)
+		}
+		opts, err := parseItemListOptions(remembered)
+		if err != nil {
+			// Ignore options that are no longer valid.
+			return parseItemListOptions(query)
+		}
+		return opts, nil
+	}
+}
+
+func rememberItemListOptions(w http.ResponseWriter, r *http.Request, opts itemListOptions) {
+	cookie := &http.Cookie{
+		Name:     itemListCookie,
+		Value:    opts.values().Encode(),
+		Path:     r.URL.Path,
+		MaxAge:   int(itemListCookieDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if cookie.Value == "" {
+		cookie.MaxAge = -1
+	}
+
+	http.SetCookie(w, cookie)
+}
+
+// listItemRows lists a page of items in order. The cursor fields of params are
+// set from cursor.
+func listItemRows(ctx context.Context, q *database.Queries, params database.ListItemsParams, order database.ItemOrder, cursor database.ItemCursor) ([]database.ListItemsRow, error) {
+	switch order {
+	case database.ItemOrderOldest:
+		params.CursorPublishedAt = cursor.Time
+		params.CursorID = cursor.ID
+		rows, err := q.ListItemsOldestFirst(ctx, database.ListItemsOldestFirstParams(params))
+		if err != nil {
+			return nil, err
+		}
+		items := make([]database.ListItemsRow, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, database.ListItemsRow(row))
+		}
+		return items, nil
+
+	case database.ItemOrderFetched:
+		rows, err := q.ListItemsByFetchedAt(ctx, database.ListItemsByFetchedAtParams{
+			UserID:             params.UserID,
+			HasStatus:          params.HasStatus,
+			Status:             params.Status,
+			HasFeedID:          params.HasFeedID,
+			FeedID:             params.FeedID,
+			HasCategoryID:      params.HasCategoryID,
+			CategoryID:         params.CategoryID,
+			StarredOnly:        params.StarredOnly,
+			HasPublishedAfter:  params.HasPublishedAfter,
+			PublishedAfter:     params.PublishedAfter,
+			HasPublishedBefore: params.HasPublishedBefore,
+			PublishedBefore:    params.PublishedBefore,
+			Author:             params.Author,
+			// created_at is set by CURRENT_TIMESTAMP, so it is stored in
+			// SQLite's format rather than the driver's, and compared as text.
+			CursorCreatedAt: cursor.Time.UTC().Format(time.DateTime),
+			CursorID:        cursor.ID,
+			Limit:           params.Limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]database.ListItemsRow, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, database.ListItemsRow(row))
+		}
+		return items, nil
+
+	default:
+		params.CursorPublishedAt = cursor.Time
+		params.CursorID = cursor.ID
+		return q.ListItems(ctx, params)
+	}
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+	"github.com/go-chi/chi/v5"
+)
+
+// readActionRetention is how long a bulk mark-as-read can be undone for.
+const readActionRetention = 24 * time.Hour
+
+// markAsRead marks the unread items selected by scope as read, and records
+// them as a read action that can be undone. The ActionID and UserID of scope
+// are set here.
+func (s *Server) markAsRead(ctx context.Context, conn *sql.Conn, scope database.AddReadActionItemsParams) (int64, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(conn).WithTx(tx)
+
+	if err := q.DeleteOldReadActions(ctx, database.DeleteOldReadActionsParams{
+		UserID: currentUserID(ctx),
+		// created_at is set by CURRENT_TIMESTAMP, so it is compared in
+		// SQLite's format.
+		Before: time.Now().Add(-readActionRetention).UTC().Format(time.DateTime),
+	}); err != nil {
+		return 0, fmt.Errorf("deleting old read actions: %w", err)
+	}
+
+	actionID, err := q.CreateReadAction(ctx, currentUserID(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("creating read action: %w", err)
+	}
+
+	scope.ActionID = actionID
+	scope.UserID = currentUserID(ctx)
+	if err := q.AddReadActionItems(ctx, scope); err != nil {
+		return 0, fmt.Errorf("recording read action items: %w", err)
+	}
+
+	if err := q.ApplyReadAction(ctx, database.ApplyReadActionParams{
+		UserID:   currentUserID(ctx),
+		ActionID: actionID,
+	}); err != nil {
+		return 0, fmt.Errorf("marking items as read: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	s.itemStateChanged(ctx)
+
+	return actionID, nil
+}
+
+// parseMarkAsReadScope reads the parts of a mark-as-read scope shared by every
+// list from the form: max_id, the newest item when the page was loaded, so
+// that items fetched since stay unread, and older_than_days.
+func parseMarkAsReadScope(r *http.Request) (database.AddReadActionItemsParams, error) {
+	if err := r.ParseForm(); err != nil {
+		return database.AddReadActionItemsParams{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	var scope database.AddReadActionItemsParams
+
+	if value := r.Form.Get("max_id"); value != "" {
+		maxID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return database.AddReadActionItemsParams{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing max ID: %w", err))
+		}
+		scope.HasMaxID, scope.MaxID = true, maxID
+	}
+
+	if value := r.Form.Get("older_than_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return database.AddReadActionItemsParams{}, NewAPIError(http.StatusBadRequest, fmt.Errorf("invalid number of days: %s", value)) //nolint:err113
+		}
+		scope.HasOlderThan, scope.OlderThan = true, time.Now().AddDate(0, 0, -days).UTC()
+	}
+
+	return scope, nil
+}
+
+// redirectAfterRead sends the client back to the page it marked items as read
+// from, offering to undo the action.
+func redirectAfterRead(w http.ResponseWriter, r *http.Request, path string, actionID int64) {
+	http.Redirect(w, r, path+"?"+url.Values{"undo": {strconv.FormatInt(actionID, 10)}}.Encode(), http.StatusFound)
+}
+
+// readActionFor returns the read action a page offers to undo, given by its
+// undo parameter. It returns the zero value if there is none, or if it can no
+// longer be undone.
+func readActionFor(ctx context.Context, q *database.Queries, r *http.Request) (database.GetReadActionRow, error) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("undo"), 10, 64)
+	if err != nil {
+		return database.GetReadActionRow{}, nil
+	}
+
+	action, err := q.GetReadAction(ctx, database.GetReadActionParams{ID: id, UserID: currentUserID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.GetReadActionRow{}, nil
+		}
+		return database.GetReadActionRow{}, fmt.Errorf("getting read action: %w", err)
+	}
+
+	return action, nil
+}
+
+func (s *Server) readAll(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	scope, err := parseMarkAsReadScope(r)
+	if err != nil {
+		return err
+	}
+
+	actionID, err := s.markAsRead(ctx, conn, scope)
+	if err != nil {
+		return err
+	}
+
+	redirectAfterRead(w, r, "/unread", actionID)
+	return nil
+}
+
+func (s *Server) readFeed(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing feed ID: %w", err))
+	}
+
+	scope, err := parseMarkAsReadScope(r)
+	if err != nil {
+		return err
+	}
+	scope.HasFeedID, scope.FeedID = true, int64(id)
+
+	actionID, err := s.markAsRead(ctx, conn, scope)
+	if err != nil {
+		return err
+	}
+
+	redirectAfterRead(w, r, fmt.Sprintf("/feeds/%d", id), actionID)
+	return nil
+}
+
+func (s *Server) readCategory(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing category ID: %w", err))
+	}
+
+	scope, err := parseMarkAsReadScope(r)
+	if err != nil {
+		return err
+	}
+	scope.HasCategoryID, scope.CategoryID = true, int64(id)
+
+	actionID, err := s.markAsRead(ctx, conn, scope)
+	if err != nil {
+		return err
+	}
+
+	redirectAfterRead(w, r, fmt.Sprintf("/categories/%d", id), actionID)
+	return nil
+}
+
+// undoReadAction marks the items of a read action that are still read as
+// unread again, and reloads the page it was undone from.
+func (s *Server) undoReadAction(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing read action ID: %w", err))
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(conn).WithTx(tx)
+
+	if _, err := q.GetReadAction(ctx, database.GetReadActionParams{ID: int64(id), UserID: currentUserID(ctx)}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("read action %d not found", id)) //nolint:err113
+		}
+		return fmt.Errorf("getting read action: %w", err)
+	}
+
+	if err := q.UndoReadAction(ctx, database.UndoReadActionParams{ID: int64(id), UserID: currentUserID(ctx)}); err != nil {
+		return fmt.Errorf("undoing read action: %w", err)
+	}
+
+	if err := q.DeleteReadAction(ctx, database.DeleteReadActionParams{ID: int64(id), UserID: currentUserID(ctx)}); err != nil {
+		return fmt.Errorf("deleting read action: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	s.itemStateChanged(ctx)
+
+	// htmx sends the page's URL. Only its path is used, without leading
+	// slashes that would make it a URL of another host, so this can't
+	// redirect off the site.
+	path := "/unread"
+	if current, err := url.Parse(r.Header.Get("HX-Current-URL")); err == nil && strings.TrimLeft(current.Path, "/") != "" {
+		path = "/" + strings.TrimLeft(current.Path, "/")
+	}
+
+	http.Redirect(w, r, path, http.StatusFound)
+	return nil
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/ethansaxenian/rss/components"
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/log"
+	"github.com/ethansaxenian/rss/rss"
+)
+
+const (
+	maxOPMLUploadSize = 10 << 20 // 10 MiB
+	opmlExportTitle   = "RSS subscriptions"
+)
+
+func (s *Server) exportOPML(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	feeds, err := q.ListFeeds(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	categories, err := q.ListCategories(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing categories: %w", err)
+	}
+
+	categoryTitles := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		categoryTitles[c.ID] = c.Title
+	}
+
+	subs := make([]rss.Subscription, 0, len(feeds))
+	for _, f := range feeds {
+		subs = append(subs, rss.Subscription{
+			Title:    f.Title,
+			URL:      f.URL,
+			Category: categoryTitles[f.CategoryID.Int64],
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="feeds.opml"`)
+	w.WriteHeader(http.StatusOK)
+
+	if err := rss.WriteOPML(w, opmlExportTitle, subs); err != nil {
+		return fmt.Errorf("writing OPML: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) importOPML(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseMultipartForm(maxOPMLUploadSize); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("reading uploaded file: %w", err))
+	}
+	defer file.Close()
+
+	subs, err := rss.ParseOPML(file)
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	categoryIDs := map[string]int64{}
+
+	var numCreated, numDuplicates int
+	failures := []string{}
+
+	for _, sub := range subs {
+		created, err := importSubscription(ctx, conn, sub, categoryIDs)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("%s: %v", sub.URL, err))
+		case created:
+			numCreated++
+		default:
+			numDuplicates++
+		}
+	}
+
+	log.Add(ctx, slog.GroupAttrs(
+		"opml_import",
+		slog.Int("created", numCreated),
+		slog.Int("duplicates", numDuplicates),
+		slog.Int("failures", len(failures)),
+	))
+
+	if numCreated > 0 {
+		s.worker.RefreshAll()
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.OPMLImportResult(numCreated, numDuplicates, failures).Render(ctx, w)
+}
+
+// importSubscription subscribes to sub, returning false if the user is already
+// subscribed to its URL. Feeds that are new to the server are refreshed by the
+// caller. categoryIDs caches the IDs of the categories created so far.
+func importSubscription(ctx context.Context, conn *sql.Conn, sub rss.Subscription, categoryIDs map[string]int64) (bool, error) {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false, rss.ErrInvalidURL
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(conn).WithTx(tx)
+
+	var categoryID sql.NullInt64
+	if sub.Category != "" {
+		id, ok := categoryIDs[sub.Category]
+		if !ok {
+			category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{Title: sub.Category, UserID: currentUserID(ctx)})
+			if err != nil {
+				return false, fmt.Errorf("creating category: %w", err)
+			}
+			id = category.ID
+		}
+		categoryID = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	title := sub.Title
+	if title == "" {
+		title = sub.URL
+	}
+
+	feed, err := q.GetFeedByURL(ctx, sub.URL)
+	if errors.Is(err, sql.ErrNoRows) {
+		feed, err = q.CreateFeed(ctx, database.CreateFeedParams{Title: title, URL: sub.URL})
+	}
+	if err != nil {
+		return false, fmt.Errorf("creating feed: %w", err)
+	}
+
+	if err := q.CreateSubscription(ctx, database.CreateSubscriptionParams{
+		UserID:     currentUserID(ctx),
+		FeedID:     feed.ID,
+		Title:      title,
+		CategoryID: categoryID,
+	}); err != nil {
+		if database.IsUniqueConstraintErr(err) {
+			return false, commitImport(tx, sub, categoryID, categoryIDs)
+		}
+		return false, fmt.Errorf("creating subscription: %w", err)
+	}
+
+	if err := q.CreateSubscriptionItemStates(ctx, database.CreateSubscriptionItemStatesParams{
+		UserID: currentUserID(ctx),
+		FeedID: feed.ID,
+	}); err != nil {
+		return false, fmt.Errorf("creating item states: %w", err)
+	}
+
+	return true, commitImport(tx, sub, categoryID, categoryIDs)
+}
+
+// commitImport commits the import of sub, and caches the ID of its category
+// once it is sure to exist.
+func commitImport(tx *sql.Tx, sub rss.Subscription, categoryID sql.NullInt64, categoryIDs map[string]int64) error {
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	if categoryID.Valid {
+		categoryIDs[sub.Category] = categoryID.Int64
+	}
+
+	return nil
+}
@@ -1,23 +1,38 @@
 package server
 
 import (
+	"cmp"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethansaxenian/rss/components"
 	"github.com/ethansaxenian/rss/contextkeys"
 	"github.com/ethansaxenian/rss/database"
 	"github.com/ethansaxenian/rss/log"
+	"github.com/ethansaxenian/rss/rss"
+	"github.com/ethansaxenian/rss/worker"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 )
 
 const (
 	defaultPageSize = 20
+	// feedFetchTimeout limits how long adding a feed waits for its site.
+	feedFetchTimeout = 15 * time.Second
+	// minRefreshIntervalMinutes is the shortest refresh interval a user can
+	// set, since the worker never refreshes more often.
+	minRefreshIntervalMinutes = int(worker.MinRefreshInterval / time.Minute)
+	// maxBulkStatusIDs is the most items one bulk status change can update.
+	// The mark-read-on-scroll script splits bigger batches to fit.
+	maxBulkStatusIDs = 100
 )
 
 func (s *Server) NewRouter() chi.Router {
@@ -25,26 +40,95 @@ func (s *Server) NewRouter() chi.Router {
 
 	r.Use(log.Middleware(s.log))
 	r.Use(middleware.Recoverer)
-	r.Use(cors.Handler(cors.Options{AllowedOrigins: []string{"*"}}))
-	r.Use(middleware.RedirectSlashes)
+	r.Use(redirectSlashesExcept("/fever/"))
 	r.Use(middleware.NoCache)
 
+	r.Group(func(r chi.Router) {
+		r.Use(s.csrf.Handler)
+
+		r.Get("/login", s.Handle(s.loginPage))
+		r.Post("/login", s.Handle(s.login))
+		r.Get("/signup", s.Handle(s.signupPage))
+		r.Post("/signup", s.Handle(s.signup))
+
+		r.Group(s.uiRoutes)
+	})
+
+	r.Get("/static/*", http.FileServerFS(staticFiles).ServeHTTP)
+	r.Post("/fever", s.Handle(s.fever))
+	r.Post("/fever/", s.Handle(s.fever))
+	r.Post("/accounts/ClientLogin", s.Handle(s.greaderLogin))
+	r.Route("/reader/api/0", s.greaderRoutes)
+	r.Route("/api/v1", s.apiRoutes)
+
+	return r
+}
+
+// uiRoutes are the pages of the web UI, which need a signed in user.
+func (s *Server) uiRoutes(r chi.Router) {
+	r.Use(s.requireSession)
+
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/unread", http.StatusMovedPermanently)
 	})
 
 	r.Get("/unread", s.Handle(s.unreadPage))
 	r.Get("/unread/list", s.Handle(s.unreadItemList))
+	r.Get("/unread/count", s.Handle(s.unreadCount))
 	r.Get("/history", s.Handle(s.historyPage))
 	r.Get("/history/list", s.Handle(s.historyItemList))
+	r.Get("/starred", s.Handle(s.starredPage))
+	r.Get("/starred/list", s.Handle(s.starredItemList))
+	r.Get("/starred/count", s.Handle(s.starredCount))
+	r.Get("/search", s.Handle(s.searchPage))
+	r.Get("/search/list", s.Handle(s.searchItemList))
 	r.Get("/feeds", s.Handle(s.feedsPage))
+	r.Post("/feeds", s.HandleFetch(s.createFeed))
+	r.Get("/feeds/export.opml", s.Handle(s.exportOPML))
+	r.Post("/feeds/import", s.Handle(s.importOPML))
 	r.Get("/feeds/{id:^[0-9]+}", s.Handle(s.feedPage))
+	r.Patch("/feeds/{id:^[0-9]+}", s.HandleFetch(s.updateFeed))
+	r.Delete("/feeds/{id:^[0-9]+}", s.Handle(s.deleteFeed))
+	r.Post("/feeds/{id:^[0-9]+}/read-all", s.Handle(s.readFeed))
 	r.Get("/feeds/{id:^[0-9]+}/list", s.Handle(s.feedItemList))
 	r.Post("/feeds/refresh", s.Handle(s.refreshFeeds))
+	r.Get("/categories/nav", s.Handle(s.categoryNav))
+	r.Get("/categories/{id:^[0-9]+}", s.Handle(s.categoryPage))
+	r.Delete("/categories/{id:^[0-9]+}", s.Handle(s.deleteCategory))
+	r.Get("/categories/{id:^[0-9]+}/list", s.Handle(s.categoryItemList))
+	r.Post("/categories/{id:^[0-9]+}/read-all", s.Handle(s.readCategory))
+	r.Get("/rules", s.Handle(s.rulesPage))
+	r.Post("/rules", s.Handle(s.createRule))
+	r.Get("/rules/preview", s.Handle(s.previewRule))
+	r.Delete("/rules/{id:^[0-9]+}", s.Handle(s.deleteRule))
+	r.Get("/items/{id:^[0-9]+}", s.Handle(s.itemPage))
+	r.Put("/items/status", s.Handle(s.bulkStatus))
 	r.Put("/items/{id:^[0-9]+}/status", s.Handle(s.status))
+	r.Put("/items/{id:^[0-9]+}/star", s.Handle(s.star))
 	r.Post("/items/read-all", s.Handle(s.readAll))
+	r.Post("/read-actions/{id:^[0-9]+}/undo", s.Handle(s.undoReadAction))
+	r.Get("/account", s.Handle(s.accountPage))
+	r.Post("/account/settings", s.Handle(s.updateReadSettings))
+	r.Post("/account/tokens", s.Handle(s.createAPIToken))
+	r.Delete("/account/tokens/{id:^[0-9]+}", s.Handle(s.deleteAPIToken))
+	r.Post("/logout", s.Handle(s.logout))
+	r.Get("/events", s.events)
+}
 
-	return r
+// redirectSlashesExcept is [middleware.RedirectSlashes], except for the given
+// paths, which clients request with a trailing slash and whose POST bodies
+// would be lost in a redirect.
+func redirectSlashesExcept(paths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		redirect := middleware.RedirectSlashes(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(paths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			redirect.ServeHTTP(w, r)
+		})
+	}
 }
 
 func (s *Server) unreadPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
@@ -54,6 +138,7 @@ func (s *Server) unreadPage(conn *sql.Conn, w http.ResponseWriter, r *http.Reque
 	count, err := q.CountItems(
 		ctx,
 		database.CountItemsParams{
+			UserID:    currentUserID(ctx),
 			HasStatus: true,
 			Status:    database.StatusUnread,
 		},
@@ -62,8 +147,18 @@ func (s *Server) unreadPage(conn *sql.Conn, w http.ResponseWriter, r *http.Reque
 		return fmt.Errorf("counting unread items: %w", err)
 	}
 
+	maxID, err := q.GetMaxItemID(ctx)
+	if err != nil {
+		return fmt.Errorf("getting newest item ID: %w", err)
+	}
+
+	undo, err := readActionFor(ctx, q, r)
+	if err != nil {
+		return err
+	}
+
 	w.WriteHeader(http.StatusOK)
-	return components.UnreadPage(count).Render(ctx, w)
+	return components.UnreadPage(count, maxID, undo).Render(ctx, w)
 }
 
 func (s *Server) historyPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
@@ -73,6 +168,7 @@ func (s *Server) historyPage(conn *sql.Conn, w http.ResponseWriter, r *http.Requ
 	count, err := q.CountItems(
 		ctx,
 		database.CountItemsParams{
+			UserID:    currentUserID(ctx),
 			HasStatus: true,
 			Status:    database.StatusRead,
 		},
@@ -85,6 +181,49 @@ func (s *Server) historyPage(conn *sql.Conn, w http.ResponseWriter, r *http.Requ
 	return components.HistoryPage(count).Render(ctx, w)
 }
 
+func (s *Server) starredPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	count, err := q.CountItems(ctx, database.CountItemsParams{UserID: currentUserID(ctx), StarredOnly: true})
+	if err != nil {
+		return fmt.Errorf("counting starred items: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.StarredPage(count).Render(ctx, w)
+}
+
+func (s *Server) starredCount(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	count, err := q.CountItems(ctx, database.CountItemsParams{UserID: currentUserID(ctx), StarredOnly: true})
+	if err != nil {
+		return fmt.Errorf("counting starred items: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.StarredCount(count).Render(ctx, w)
+}
+
+func (s *Server) unreadCount(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	count, err := q.CountItems(ctx, database.CountItemsParams{
+		UserID:    currentUserID(ctx),
+		HasStatus: true,
+		Status:    database.StatusUnread,
+	})
+	if err != nil {
+		return fmt.Errorf("counting unread items: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.UnreadCount(count).Render(ctx, w)
+}
+
 func (s *Server) feedPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
 	ctx := r.Context()
 
@@ -94,8 +233,11 @@ func (s *Server) feedPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request
 	}
 
 	q := database.New(conn)
-	feed, err := q.GetFeed(ctx, int64(id))
+	feed, err := q.GetUserFeed(ctx, database.GetUserFeedParams{ID: int64(id), UserID: currentUserID(ctx)})
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("feed %d not found", id)) //nolint:err113
+		}
 		return fmt.Errorf("getting feed: %w", err)
 	}
 
@@ -104,6 +246,7 @@ func (s *Server) feedPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request
 	count, err := q.CountItems(
 		ctx,
 		database.CountItemsParams{
+			UserID:    currentUserID(ctx),
 			HasFeedID: true,
 			FeedID:    int64(id),
 		},
@@ -112,47 +255,105 @@ func (s *Server) feedPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request
 		return fmt.Errorf("counting read items: %w", err)
 	}
 
+	categories, err := q.ListCategories(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing categories: %w", err)
+	}
+
+	maxID, err := q.GetMaxItemID(ctx)
+	if err != nil {
+		return fmt.Errorf("getting newest item ID: %w", err)
+	}
+
+	undo, err := readActionFor(ctx, q, r)
+	if err != nil {
+		return err
+	}
+
 	w.WriteHeader(http.StatusOK)
-	return components.FeedPage(feed, count).Render(ctx, w)
+	return components.FeedPage(feed, count, categories, maxID, undo).Render(ctx, w)
 }
 
-func (s *Server) listItems(conn *sql.Conn, w http.ResponseWriter, r *http.Request, status database.Status, feedID int64) error {
+// itemsFilter selects the items shown by an item list. Zero values don't
+// filter.
+type itemsFilter struct {
+	status      database.Status
+	feedID      int64
+	categoryID  int64
+	starredOnly bool
+}
+
+func (s *Server) listItems(conn *sql.Conn, w http.ResponseWriter, r *http.Request, filter itemsFilter) error {
 	ctx := r.Context()
 
-	query := r.URL.Query()
-	page, err := strconv.Atoi(query.Get("page"))
+	opts, err := itemListOptionsFor(w, r)
 	if err != nil {
-		page = 0
+		return err
 	}
 
+	cursor := database.FirstPageCursor(opts.order)
+	firstPage := true
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		cursor, err = database.ParseItemCursor(v)
+		if err != nil {
+			return NewAPIError(http.StatusBadRequest, err)
+		}
+		firstPage = false
+	}
+
+	publishedAfter, publishedBefore := opts.publishedRange(time.Now())
+
 	q := database.New(conn)
-	items, err := q.ListItems(
+	items, err := listItemRows(
 		ctx,
+		q,
 		database.ListItemsParams{
-			HasStatus: status != database.StatusAny,
-			Status:    status,
-			HasFeedID: feedID != 0,
-			FeedID:    feedID,
-			Limit:     defaultPageSize,
-			Offset:    int64(page) * defaultPageSize,
+			UserID:             currentUserID(ctx),
+			HasStatus:          filter.status != database.StatusAny,
+			Status:             filter.status,
+			HasFeedID:          filter.feedID != 0,
+			FeedID:             filter.feedID,
+			HasCategoryID:      filter.categoryID != 0,
+			CategoryID:         filter.categoryID,
+			StarredOnly:        filter.starredOnly,
+			HasPublishedAfter:  !publishedAfter.IsZero(),
+			PublishedAfter:     publishedAfter,
+			HasPublishedBefore: !publishedBefore.IsZero(),
+			PublishedBefore:    publishedBefore,
+			Author:             opts.author,
+			Limit:              defaultPageSize,
 		},
+		opts.order,
+		cursor,
 	)
 	if err != nil {
-		return fmt.Errorf("listing %s items: %w", status, err)
+		return fmt.Errorf("listing %s items: %w", filter.status, err)
 	}
 
-	ctx = contextkeys.WithRoutePathCtx(r.Context(), r.URL.Path)
+	// Next pages are loaded from the route path, so it keeps the options.
+	routePath := r.URL.Path
+	if values := opts.values(); len(values) > 0 {
+		routePath += "?" + values.Encode()
+	}
+	ctx = contextkeys.WithRoutePathCtx(r.Context(), routePath)
 
 	w.WriteHeader(http.StatusOK)
-	return components.ItemsList(items, page).Render(ctx, w)
+	if firstPage {
+		return components.FilteredItemsList(items, opts.order, r.URL.Path, opts.values()).Render(ctx, w)
+	}
+	return components.ItemsList(items, opts.order).Render(ctx, w)
 }
 
 func (s *Server) unreadItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
-	return s.listItems(conn, w, r, database.StatusUnread, 0)
+	return s.listItems(conn, w, r, itemsFilter{status: database.StatusUnread})
 }
 
 func (s *Server) historyItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
-	return s.listItems(conn, w, r, database.StatusRead, 0)
+	return s.listItems(conn, w, r, itemsFilter{status: database.StatusRead})
+}
+
+func (s *Server) starredItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	return s.listItems(conn, w, r, itemsFilter{starredOnly: true})
 }
 
 func (s *Server) feedItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
@@ -161,7 +362,135 @@ func (s *Server) feedItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Req
 		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing feed ID: %w", err))
 	}
 
-	return s.listItems(conn, w, r, database.StatusAny, int64(id))
+	return s.listItems(conn, w, r, itemsFilter{feedID: int64(id)})
+}
+
+func (s *Server) categoryPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing category ID: %w", err))
+	}
+
+	q := database.New(conn)
+	category, err := q.GetCategory(ctx, database.GetCategoryParams{ID: int64(id), UserID: currentUserID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("category %d not found", id)) //nolint:err113
+		}
+		return fmt.Errorf("getting category: %w", err)
+	}
+
+	count, err := q.CountItems(
+		ctx,
+		database.CountItemsParams{
+			UserID:        currentUserID(ctx),
+			HasStatus:     true,
+			Status:        database.StatusUnread,
+			HasCategoryID: true,
+			CategoryID:    category.ID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("counting unread items: %w", err)
+	}
+
+	maxID, err := q.GetMaxItemID(ctx)
+	if err != nil {
+		return fmt.Errorf("getting newest item ID: %w", err)
+	}
+
+	undo, err := readActionFor(ctx, q, r)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.CategoryPage(category, count, maxID, undo).Render(ctx, w)
+}
+
+func (s *Server) categoryItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing category ID: %w", err))
+	}
+
+	return s.listItems(conn, w, r, itemsFilter{status: database.StatusUnread, categoryID: int64(id)})
+}
+
+func (s *Server) categoryNav(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	counts, err := q.ListCategoryUnreadCounts(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("counting unread items per category: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.CategoryNav(counts).Render(ctx, w)
+}
+
+func (s *Server) deleteCategory(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing category ID: %w", err))
+	}
+
+	// Feeds in the category are kept and become uncategorized.
+	q := database.New(conn)
+	if err := q.DeleteCategory(ctx, database.DeleteCategoryParams{ID: int64(id), UserID: currentUserID(ctx)}); err != nil {
+		return fmt.Errorf("deleting category: %w", err)
+	}
+
+	w.Header().Set("HX-Location", `{"path": "/feeds", "target": "#container"}`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) itemPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing item ID: %w", err))
+	}
+
+	q := database.New(conn)
+	row, err := q.GetItem(ctx, database.GetItemParams{ID: int64(id), UserID: currentUserID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("item %d not found", id)) //nolint:err113
+		}
+		return fmt.Errorf("getting item: %w", err)
+	}
+
+	log.Add(ctx, row.UserItem.LogValue())
+
+	if contextkeys.GetUserCtx(ctx).MarkReadOnOpen && row.UserItem.Status == database.StatusUnread {
+		if err := q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{
+			Status: database.StatusRead,
+			ID:     row.UserItem.ID,
+			UserID: currentUserID(ctx),
+		}); err != nil {
+			return fmt.Errorf("updating item status: %w", err)
+		}
+		row.UserItem.Status = database.StatusRead
+		s.itemStateChanged(ctx)
+	}
+
+	// Show the extracted article by default when the user fetches full
+	// articles. Another subscriber may have had it extracted, so it can still
+	// be picked when they don't.
+	view := r.URL.Query().Get("view")
+	showOriginal := row.UserItem.ExtractedContent != "" &&
+		(view == "original" || (view != "feed" && row.UserFeed.FetchFullContent))
+
+	w.WriteHeader(http.StatusOK)
+	return components.ItemPage(row.UserItem, row.UserFeed, showOriginal).Render(ctx, w)
 }
 
 func (s *Server) status(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
@@ -180,41 +509,593 @@ func (s *Server) status(conn *sql.Conn, w http.ResponseWriter, r *http.Request)
 	}
 
 	q := database.New(conn)
-	item, err := q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{Status: status, ID: int64(id)})
+	row, err := q.GetItem(ctx, database.GetItemParams{ID: int64(id), UserID: currentUserID(ctx)})
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("item %d not found", id)) //nolint:err113
+		}
+		return fmt.Errorf("getting item: %w", err)
+	}
+
+	if err := q.UpdateItemStatus(ctx, database.UpdateItemStatusParams{
+		Status: status,
+		ID:     row.UserItem.ID,
+		UserID: currentUserID(ctx),
+	}); err != nil {
 		return fmt.Errorf("updating item status: %w", err)
 	}
+	row.UserItem.Status = status
+	s.itemStateChanged(ctx)
+
+	w.WriteHeader(http.StatusOK)
+	return components.MarkAs(row.UserItem).Render(ctx, w)
+}
+
+func (s *Server) star(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing item ID: %w", err))
+	}
+
+	starred, err := strconv.ParseBool(r.URL.Query().Get("starred"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing starred: %w", err))
+	}
+
+	q := database.New(conn)
+	row, err := q.GetItem(ctx, database.GetItemParams{ID: int64(id), UserID: currentUserID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("item %d not found", id)) //nolint:err113
+		}
+		return fmt.Errorf("getting item: %w", err)
+	}
+
+	if err := q.UpdateItemStarred(ctx, database.UpdateItemStarredParams{
+		Starred: starred,
+		ID:      row.UserItem.ID,
+		UserID:  currentUserID(ctx),
+	}); err != nil {
+		return fmt.Errorf("updating item starred: %w", err)
+	}
+	row.UserItem.Starred = starred
+	s.itemStateChanged(ctx)
 
 	w.WriteHeader(http.StatusOK)
-	return components.MarkAs(item).Render(ctx, w)
+	return components.Star(row.UserItem).Render(ctx, w)
 }
 
 func (s *Server) feedsPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
 	ctx := r.Context()
 
+	brokenOnly := r.URL.Query().Has("broken")
+
 	q := database.New(conn)
-	feeds, err := q.ListFeeds(ctx)
+
+	var feeds []database.UserFeed
+	var err error
+	if brokenOnly {
+		feeds, err = q.ListBrokenFeeds(ctx, currentUserID(ctx))
+	} else {
+		feeds, err = q.ListFeeds(ctx, currentUserID(ctx))
+	}
 	if err != nil {
 		return fmt.Errorf("listing feeds: %w", err)
 	}
 
+	categories, err := q.ListCategories(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing categories: %w", err)
+	}
+
+	unreadCounts, err := feedUnreadCounts(ctx, q)
+	if err != nil {
+		return err
+	}
+
 	w.WriteHeader(http.StatusOK)
-	return components.FeedsPage(feeds).Render(ctx, w)
+	return components.FeedsPage(feeds, categories, unreadCounts, brokenOnly).Render(ctx, w)
 }
 
-func (s *Server) readAll(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+// feedUnreadCounts returns the number of unread items in each of the signed in
+// user's feeds. Feeds without any are left out.
+func feedUnreadCounts(ctx context.Context, q *database.Queries) (map[int64]int64, error) {
+	rows, err := q.ListFeedUnreadCounts(ctx, currentUserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("counting unread items: %w", err)
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.FeedID] = row.UnreadCount
+	}
+
+	return counts, nil
+}
+
+func (s *Server) createFeed(w http.ResponseWriter, r *http.Request) error {
 	ctx := r.Context()
 
-	q := database.New(conn)
-	err := q.MarkAllItemsAsRead(ctx)
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	links, err := discoverFeeds(ctx, r.PostForm.Get("url"))
 	if err != nil {
-		return fmt.Errorf("marking items as read: %w", err)
+		return err
+	}
+
+	if len(links) > 1 {
+		w.WriteHeader(http.StatusOK)
+		return components.FeedCandidates(links).Render(ctx, w)
+	}
+
+	if _, err := s.subscribe(ctx, links[0].URL, "", sql.NullInt64{}); err != nil {
+		return err
 	}
 
-	http.Redirect(w, r, "/unread", http.StatusFound)
+	w.Header().Set("HX-Location", `{"path": "/feeds", "target": "#container"}`)
+	w.WriteHeader(http.StatusCreated)
 	return nil
 }
 
+// discoverFeeds returns the feeds available at rawURL, giving up after
+// feedFetchTimeout.
+func discoverFeeds(ctx context.Context, rawURL string) ([]rss.FeedLink, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+
+	links, err := rss.DiscoverFeeds(ctx, rawURL)
+	if err != nil {
+		if errors.Is(err, rss.ErrInvalidURL) || errors.Is(err, rss.ErrNoFeedsFound) {
+			return nil, NewAPIError(http.StatusBadRequest, err)
+		}
+		return nil, NewAPIError(http.StatusBadGateway, fmt.Errorf("discovering feeds: %w", err))
+	}
+
+	return links, nil
+}
+
+// subscribe subscribes the user to feedURL. Feeds are shared, so a URL that
+// is new to the server is fetched and queued for its first refresh, while an
+// existing feed is reused with the items it already has. A blank title falls
+// back to the feed's own.
+//
+// The feed is fetched before a conn is taken from s.db, so callers shouldn't
+// hold one.
+func (s *Server) subscribe(ctx context.Context, feedURL, title string, categoryID sql.NullInt64) (database.UserFeed, error) {
+	feedTitle, err := fetchNewFeedTitle(ctx, database.New(s.db), feedURL)
+	if err != nil {
+		return database.UserFeed{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.UserFeed{}, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(s.db).WithTx(tx)
+
+	userFeed, isNew, err := createSubscription(ctx, q, feedURL, feedTitle, title, categoryID)
+	if err != nil {
+		return database.UserFeed{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.UserFeed{}, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	log.Add(ctx, userFeed.LogValue())
+
+	if isNew {
+		s.worker.RefreshFeed(userFeed.ID)
+	}
+
+	return userFeed, nil
+}
+
+// fetchNewFeedTitle fetches feedURL and returns its title if there is no feed
+// for it yet. For an existing feed it returns "" without fetching.
+func fetchNewFeedTitle(ctx context.Context, q *database.Queries, feedURL string) (string, error) {
+	_, err := q.GetFeedByURL(ctx, feedURL)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fetchFeedTitle(ctx, feedURL)
+	case err != nil:
+		return "", fmt.Errorf("getting feed: %w", err)
+	default:
+		return "", nil
+	}
+}
+
+// createSubscription subscribes the user to feedURL within q's transaction,
+// creating the feed with feedTitle if there is none, and reports whether it
+// did.
+func createSubscription(
+	ctx context.Context,
+	q *database.Queries,
+	feedURL, feedTitle, title string,
+	categoryID sql.NullInt64,
+) (database.UserFeed, bool, error) {
+	// Look the feed up again, since it may have been added or deleted while
+	// it was fetched.
+	isNew := false
+	feed, err := q.GetFeedByURL(ctx, feedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		isNew = true
+		feed, err = q.CreateFeed(ctx, database.CreateFeedParams{Title: cmp.Or(feedTitle, feedURL), URL: feedURL})
+	}
+	if err != nil {
+		return database.UserFeed{}, false, fmt.Errorf("creating feed: %w", err)
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = feed.Title
+	}
+
+	if err := q.CreateSubscription(ctx, database.CreateSubscriptionParams{
+		UserID:     currentUserID(ctx),
+		FeedID:     feed.ID,
+		Title:      title,
+		CategoryID: categoryID,
+	}); err != nil {
+		if database.IsUniqueConstraintErr(err) {
+			return database.UserFeed{}, false, NewAPIError(http.StatusConflict, fmt.Errorf("already subscribed to %s", feedURL)) //nolint:err113
+		}
+		return database.UserFeed{}, false, fmt.Errorf("creating subscription: %w", err)
+	}
+
+	if err := q.CreateSubscriptionItemStates(ctx, database.CreateSubscriptionItemStatesParams{
+		UserID: currentUserID(ctx),
+		FeedID: feed.ID,
+	}); err != nil {
+		return database.UserFeed{}, false, fmt.Errorf("creating item states: %w", err)
+	}
+
+	userFeed, err := q.GetUserFeed(ctx, database.GetUserFeedParams{ID: feed.ID, UserID: currentUserID(ctx)})
+	if err != nil {
+		return database.UserFeed{}, false, fmt.Errorf("getting feed: %w", err)
+	}
+
+	return userFeed, isNew, nil
+}
+
+// fetchFeedTitle fetches feedURL to check that it is a feed, giving up after
+// feedFetchTimeout, and returns its title.
+func fetchFeedTitle(ctx context.Context, feedURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+
+	res, err := rss.FetchFeed(ctx, feedURL)
+	if err != nil {
+		return "", NewAPIError(http.StatusBadGateway, fmt.Errorf("fetching feed: %w", err))
+	}
+
+	return strings.TrimSpace(res.Title), nil
+}
+
+func (s *Server) updateFeed(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing feed ID: %w", err))
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	q := database.New(s.db)
+	feed, err := q.GetUserFeed(ctx, database.GetUserFeedParams{ID: int64(id), UserID: currentUserID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewAPIError(http.StatusNotFound, fmt.Errorf("feed %d not found", id)) //nolint:err113
+		}
+		return fmt.Errorf("getting feed: %w", err)
+	}
+
+	title := strings.TrimSpace(r.PostForm.Get("title"))
+	if title == "" {
+		title = feed.Title
+	}
+
+	url := strings.TrimSpace(r.PostForm.Get("url"))
+	if url == "" {
+		url = feed.URL
+	}
+
+	categoryID := feed.CategoryID
+	if r.PostForm.Has("category") {
+		categoryID = sql.NullInt64{}
+		if title := strings.TrimSpace(r.PostForm.Get("category")); title != "" {
+			category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{Title: title, UserID: currentUserID(ctx)})
+			if err != nil {
+				return fmt.Errorf("creating category: %w", err)
+			}
+			categoryID = sql.NullInt64{Int64: category.ID, Valid: true}
+		}
+	}
+
+	refreshInterval, err := optionalIntField(r.PostForm, "refresh_interval", feed.RefreshIntervalMinutes, minRefreshIntervalMinutes)
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	retentionReadDays, err := optionalIntField(r.PostForm, "retention_read_days", feed.RetentionReadDays, 0)
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	retentionMaxItems, err := optionalIntField(r.PostForm, "retention_max_items", feed.RetentionMaxItems, 0)
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	fetchFullContent := feed.FetchFullContent
+	if values := r.PostForm["fetch_full_content"]; len(values) > 0 {
+		// The form sends a hidden "false" before the checkbox, so the last
+		// value wins.
+		fetchFullContent = values[len(values)-1] == "true"
+	}
+
+	feed, err = s.saveFeed(ctx, feed, url, database.UpdateSubscriptionParams{
+		Title:                  title,
+		CategoryID:             categoryID,
+		RefreshIntervalMinutes: refreshInterval,
+		FetchFullContent:       fetchFullContent,
+		RetentionReadDays:      retentionReadDays,
+		RetentionMaxItems:      retentionMaxItems,
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("HX-Location", fmt.Sprintf(`{"path": "/feeds/%d", "target": "#container"}`, feed.ID))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// saveFeed updates the user's subscription to feed with sub. Settings belong
+// to the subscription, so they don't change the feed for other subscribers.
+// It queues a refresh if the changes need one.
+//
+// Changing the URL moves the subscription to the feed at feedURL instead of
+// editing the shared feed, keeping the user's settings, rules, and the read
+// and starred state of their items. A feed that is new to the server starts
+// with a copy of the old feed's items, while an existing one keeps its own
+// and takes the state of the items the two have in common.
+//
+// The new feed is fetched before a conn is taken from s.db, so callers
+// shouldn't hold one.
+func (s *Server) saveFeed(
+	ctx context.Context,
+	feed database.UserFeed,
+	feedURL string,
+	sub database.UpdateSubscriptionParams,
+) (database.UserFeed, error) {
+	sub.FeedID, sub.UserID = feed.ID, currentUserID(ctx)
+
+	urlChanged := feedURL != feed.URL
+	var feedTitle string
+	if urlChanged {
+		var err error
+		if feedTitle, err = fetchNewFeedTitle(ctx, database.New(s.db), feedURL); err != nil {
+			return database.UserFeed{}, err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.UserFeed{}, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(s.db).WithTx(tx)
+
+	isNew := false
+	if urlChanged {
+		var newFeed database.UserFeed
+		newFeed, isNew, err = createSubscription(ctx, q, feedURL, feedTitle, sub.Title, sub.CategoryID)
+		if err != nil {
+			return database.UserFeed{}, err
+		}
+
+		if err := moveSubscription(ctx, q, feed.ID, newFeed.ID, isNew); err != nil {
+			return database.UserFeed{}, err
+		}
+		sub.FeedID = newFeed.ID
+	}
+
+	if err := q.UpdateSubscription(ctx, sub); err != nil {
+		return database.UserFeed{}, fmt.Errorf("updating subscription: %w", err)
+	}
+
+	updated, err := q.GetUserFeed(ctx, database.GetUserFeedParams{ID: sub.FeedID, UserID: sub.UserID})
+	if err != nil {
+		return database.UserFeed{}, fmt.Errorf("getting feed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.UserFeed{}, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	log.Add(ctx, updated.LogValue())
+
+	if isNew || (updated.FetchFullContent && !feed.FetchFullContent) {
+		s.worker.RefreshFeed(updated.ID)
+	}
+
+	return updated, nil
+}
+
+// moveSubscription moves the user's rules and item state from oldFeedID to
+// newFeedID, which they have just subscribed to, then unsubscribes them from
+// oldFeedID. If copyItems is set, the old feed's items are copied to the new
+// one first. Refreshes find items by hash, so the copies are updated rather
+// than added again when the new feed lists the same items.
+func moveSubscription(ctx context.Context, q *database.Queries, oldFeedID, newFeedID int64, copyItems bool) error {
+	userID := currentUserID(ctx)
+
+	if copyItems {
+		if err := q.CopyFeedItems(ctx, database.CopyFeedItemsParams{NewFeedID: newFeedID, OldFeedID: oldFeedID}); err != nil {
+			return fmt.Errorf("copying items: %w", err)
+		}
+
+		if err := q.CreateSubscriptionItemStates(ctx, database.CreateSubscriptionItemStatesParams{
+			UserID: userID,
+			FeedID: newFeedID,
+		}); err != nil {
+			return fmt.Errorf("creating item states: %w", err)
+		}
+	}
+
+	states, err := q.ListMatchingItemStates(ctx, database.ListMatchingItemStatesParams{
+		UserID:    userID,
+		OldFeedID: oldFeedID,
+		NewFeedID: newFeedID,
+	})
+	if err != nil {
+		return fmt.Errorf("listing item states: %w", err)
+	}
+
+	for _, state := range states {
+		if err := q.UpdateItemState(ctx, database.UpdateItemStateParams{
+			Status:  state.Status,
+			Starred: state.Starred,
+			Tags:    state.Tags,
+			ID:      state.ItemID,
+			UserID:  userID,
+		}); err != nil {
+			return fmt.Errorf("updating item state: %w", err)
+		}
+
+		// Changing the status set read_at to now, so put back when the item
+		// was read for the retention policy.
+		if state.ReadAt.Valid {
+			if err := q.UpdateItemReadAt(ctx, database.UpdateItemReadAtParams{
+				ReadAt: state.ReadAt.Time.UTC().Format(time.DateTime),
+				ID:     state.ItemID,
+				UserID: userID,
+			}); err != nil {
+				return fmt.Errorf("updating item read time: %w", err)
+			}
+		}
+	}
+
+	// Rules are deleted with the subscription, so move them first.
+	if err := q.MoveFeedRules(ctx, database.MoveFeedRulesParams{
+		NewFeedID: sql.NullInt64{Int64: newFeedID, Valid: true},
+		OldFeedID: sql.NullInt64{Int64: oldFeedID, Valid: true},
+		UserID:    userID,
+	}); err != nil {
+		return fmt.Errorf("moving rules: %w", err)
+	}
+
+	if err := q.DeleteSubscription(ctx, database.DeleteSubscriptionParams{FeedID: oldFeedID, UserID: userID}); err != nil {
+		return fmt.Errorf("deleting subscription: %w", err)
+	}
+
+	return nil
+}
+
+// optionalIntField parses an optional integer form field that is at least
+// minValue. A blank value clears it, and a missing field keeps current.
+func optionalIntField(form url.Values, key string, current sql.NullInt64, minValue int) (sql.NullInt64, error) {
+	if !form.Has(key) {
+		return current, nil
+	}
+
+	v := strings.TrimSpace(form.Get(key))
+	if v == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < minValue {
+		return sql.NullInt64{}, fmt.Errorf("invalid %s: %s", strings.ReplaceAll(key, "_", " "), v) //nolint:err113
+	}
+
+	return sql.NullInt64{Int64: int64(n), Valid: true}, nil
+}
+
+func (s *Server) deleteFeed(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing feed ID: %w", err))
+	}
+
+	q := database.New(conn)
+	// The feed and its items are deleted with its last subscriber.
+	if err := q.DeleteSubscription(ctx, database.DeleteSubscriptionParams{FeedID: int64(id), UserID: currentUserID(ctx)}); err != nil {
+		return fmt.Errorf("deleting subscription: %w", err)
+	}
+
+	w.Header().Set("HX-Location", `{"path": "/feeds", "target": "#container"}`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// bulkStatus sets the status of every item given by an id parameter, and
+// renders their mark-as buttons out of band.
+func (s *Server) bulkStatus(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	status := database.Status(r.Form.Get("status"))
+	if !slices.Contains(database.AllStatusValues(), status) {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("unknown status: %s", status)) //nolint:err113
+	}
+
+	if len(r.Form["id"]) > maxBulkStatusIDs {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("too many item IDs: %d, the most is %d", len(r.Form["id"]), maxBulkStatusIDs)) //nolint:err113
+	}
+
+	ids := make([]int64, 0, len(r.Form["id"]))
+	for _, value := range r.Form["id"] {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing item ID: %w", err))
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return NewAPIError(http.StatusBadRequest, errors.New("no item IDs given")) //nolint:err113
+	}
+
+	q := database.New(conn)
+	if err := q.UpdateItemsStatus(ctx, database.UpdateItemsStatusParams{
+		Status: status,
+		Ids:    ids,
+		UserID: currentUserID(ctx),
+	}); err != nil {
+		return fmt.Errorf("updating item statuses: %w", err)
+	}
+	s.itemStateChanged(ctx)
+
+	rows, err := q.ListItemsByID(ctx, database.ListItemsByIDParams{Ids: ids, UserID: currentUserID(ctx)})
+	if err != nil {
+		return fmt.Errorf("listing items: %w", err)
+	}
+
+	items := make([]database.UserItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, row.UserItem)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.MarkedAs(items).Render(ctx, w)
+}
+
 func (s *Server) refreshFeeds(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
 	s.worker.RefreshAll()
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBulkStatus(t *testing.T) {
+	f := newTestFixture(t)
+
+	cookie := f.session(t, f.alice, time.Hour)
+
+	// form marks the fixture's first n items as read, repeating them to make
+	// up more IDs than it has.
+	form := func(n int) string {
+		values := url.Values{"status": {"read"}}
+		for i := range n {
+			values.Add("id", strconv.FormatInt(f.allItems[i%len(f.allItems)], 10))
+		}
+		return values.Encode()
+	}
+
+	tests := []struct {
+		name       string
+		numIDs     int
+		wantStatus int
+	}{
+		{name: "none", numIDs: 0, wantStatus: http.StatusBadRequest},
+		{name: "some", numIDs: 2, wantStatus: http.StatusOK},
+		{name: "most", numIDs: maxBulkStatusIDs, wantStatus: http.StatusOK},
+		{name: "too many", numIDs: maxBulkStatusIDs + 1, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{"Cookie": {cookie}, "Content-Type": {"application/x-www-form-urlencoded"}}
+			resp, body := f.request(t, http.MethodPut, "/items/status", header, form(tt.numIDs))
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+		})
+	}
+}
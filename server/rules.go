@@ -0,0 +1,166 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethansaxenian/rss/components"
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/rss"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// rulePreviewScanSize is how many of the most recent items a rule preview
+	// is evaluated against.
+	rulePreviewScanSize = 1000
+	rulePreviewShown    = 20
+)
+
+func (s *Server) rulesPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := database.New(conn)
+	rules, err := q.ListRules(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing rules: %w", err)
+	}
+
+	feeds, err := q.ListFeeds(ctx, currentUserID(ctx))
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.RulesPage(rules, feeds).Render(ctx, w)
+}
+
+// parseRule reads and validates a rule from the form values. An empty feed
+// makes the rule apply to every feed.
+func parseRule(values url.Values) (rss.Rule, error) {
+	var feedID sql.NullInt64
+	if v := values.Get("feed"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return rss.Rule{}, fmt.Errorf("parsing feed ID: %w", err)
+		}
+		feedID = sql.NullInt64{Int64: int64(id), Valid: true}
+	}
+
+	action := database.RuleAction(values.Get("action"))
+
+	var tag string
+	if action == database.RuleActionTag {
+		tag = strings.TrimSpace(values.Get("tag"))
+	}
+
+	return rss.CompileRule(database.Rule{
+		FeedID:    feedID,
+		Field:     database.RuleField(values.Get("field")),
+		MatchType: database.RuleMatch(values.Get("match_type")),
+		Pattern:   values.Get("pattern"),
+		Action:    action,
+		Tag:       tag,
+	})
+}
+
+func (s *Server) createRule(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing form: %w", err))
+	}
+
+	rule, err := parseRule(r.PostForm)
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	q := database.New(conn)
+
+	if rule.FeedID.Valid {
+		if _, err := q.GetUserFeed(ctx, database.GetUserFeedParams{ID: rule.FeedID.Int64, UserID: currentUserID(ctx)}); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewAPIError(http.StatusBadRequest, fmt.Errorf("feed %d not found", rule.FeedID.Int64)) //nolint:err113
+			}
+			return fmt.Errorf("getting feed: %w", err)
+		}
+	}
+
+	if _, err := q.CreateRule(ctx, database.CreateRuleParams{
+		UserID:    currentUserID(ctx),
+		FeedID:    rule.FeedID,
+		Field:     rule.Field,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		Action:    rule.Action,
+		Tag:       rule.Tag,
+	}); err != nil {
+		return fmt.Errorf("creating rule: %w", err)
+	}
+
+	w.Header().Set("HX-Location", `{"path": "/rules", "target": "#container"}`)
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+func (s *Server) deleteRule(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, fmt.Errorf("parsing rule ID: %w", err))
+	}
+
+	q := database.New(conn)
+	if err := q.DeleteRule(ctx, database.DeleteRuleParams{ID: int64(id), UserID: currentUserID(ctx)}); err != nil {
+		return fmt.Errorf("deleting rule: %w", err)
+	}
+
+	w.Header().Set("HX-Location", `{"path": "/rules", "target": "#container"}`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// previewRule is a dry run of a rule against the most recent existing items.
+// Nothing is changed.
+func (s *Server) previewRule(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	rule, err := parseRule(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusOK)
+		return components.RulePreview(nil, 0, 0, err.Error()).Render(ctx, w)
+	}
+
+	q := database.New(conn)
+	items, err := q.ListRecentItems(ctx, database.ListRecentItemsParams{
+		UserID:  currentUserID(ctx),
+		HasFeed: rule.FeedID.Valid,
+		FeedID:  rule.FeedID.Int64,
+		Limit:   rulePreviewScanSize,
+	})
+	if err != nil {
+		return fmt.Errorf("listing items: %w", err)
+	}
+
+	matches := []database.Item{}
+	var total int
+	for _, item := range items {
+		if !rule.MatchesItem(item) {
+			continue
+		}
+		total++
+		if len(matches) < rulePreviewShown {
+			matches = append(matches, item)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.RulePreview(matches, total, len(items), "").Render(ctx, w)
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ethansaxenian/rss/components"
+	"github.com/ethansaxenian/rss/contextkeys"
+	"github.com/ethansaxenian/rss/database"
+)
+
+var errEmptySearch = errors.New("search query must contain at least one term")
+
+// searchQuery is a parsed search box query.
+type searchQuery struct {
+	// match is the FTS5 expression for the free-text terms.
+	match     string
+	status    database.Status
+	feedTitle string
+}
+
+// parseSearchQuery parses a search box query. Bare words match as-is, words
+// ending in * match as prefixes, and "quoted text" matches as a phrase. The
+// qualifiers feed:<title> and status:<read|unread> filter the results.
+func parseSearchQuery(raw string) (searchQuery, error) {
+	var sq searchQuery
+	terms := []string{}
+
+	for _, token := range tokenizeSearchQuery(raw) {
+		if name, value, ok := strings.Cut(token, ":"); ok && !strings.HasPrefix(token, `"`) {
+			value = strings.Trim(value, `"`)
+
+			switch strings.ToLower(name) {
+			case "feed":
+				sq.feedTitle = value
+				continue
+			case "status":
+				status := database.Status(strings.ToLower(value))
+				if !slices.Contains(database.AllStatusValues(), status) {
+					return searchQuery{}, fmt.Errorf("unknown status: %s", value) //nolint:err113
+				}
+				sq.status = status
+				continue
+			}
+		}
+
+		prefix := strings.HasSuffix(token, "*")
+		text := strings.Trim(strings.TrimSuffix(token, "*"), `"`)
+		if text == "" {
+			continue
+		}
+
+		// Quote every term so FTS5 operators and punctuation in the input
+		// are matched literally.
+		term := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 0 {
+		return searchQuery{}, errEmptySearch
+	}
+
+	sq.match = strings.Join(terms, " ")
+
+	return sq, nil
+}
+
+// tokenizeSearchQuery splits raw on whitespace, keeping quoted sections
+// (including qualifier values like feed:"Hacker News") together.
+func tokenizeSearchQuery(raw string) []string {
+	tokens := []string{}
+
+	var b strings.Builder
+	inQuotes := false
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+
+	return tokens
+}
+
+func (s *Server) searchPage(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	raw := strings.TrimSpace(r.URL.Query().Get("q"))
+	if raw == "" {
+		w.WriteHeader(http.StatusOK)
+		return components.SearchPage(raw, 0, "").Render(ctx, w)
+	}
+
+	sq, err := parseSearchQuery(raw)
+	if err != nil {
+		w.WriteHeader(http.StatusOK)
+		return components.SearchPage(raw, 0, err.Error()).Render(ctx, w)
+	}
+
+	q := database.New(conn)
+	count, err := q.CountSearchItems(ctx, sq.params(currentUserID(ctx), 0))
+	if err != nil {
+		return fmt.Errorf("counting search results: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return components.SearchPage(raw, count, "").Render(ctx, w)
+}
+
+func (s *Server) searchItemList(conn *sql.Conn, w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+
+	sq, err := parseSearchQuery(query.Get("q"))
+	if err != nil {
+		return NewAPIError(http.StatusBadRequest, err)
+	}
+
+	q := database.New(conn)
+	matches, err := q.SearchItems(ctx, sq.params(currentUserID(ctx), page))
+	if err != nil {
+		return fmt.Errorf("searching items: %w", err)
+	}
+
+	ids := make([]int64, 0, len(matches))
+	snippets := make(map[int64]string, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+		snippets[m.ID] = m.Snippet
+	}
+
+	rows, err := q.ListItemsByID(ctx, database.ListItemsByIDParams{Ids: ids, UserID: currentUserID(ctx)})
+	if err != nil {
+		return fmt.Errorf("listing matched items: %w", err)
+	}
+
+	// Restore the rank order of the search results.
+	items := make([]database.ListItemsRow, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, database.ListItemsRow(row))
+	}
+	slices.SortFunc(items, func(a, b database.ListItemsRow) int {
+		return slices.Index(ids, a.UserItem.ID) - slices.Index(ids, b.UserItem.ID)
+	})
+
+	ctx = contextkeys.WithRoutePathCtx(ctx, r.URL.RequestURI())
+
+	w.WriteHeader(http.StatusOK)
+	return components.SearchResults(items, snippets, page).Render(ctx, w)
+}
+
+func (sq searchQuery) params(userID int64, page int) database.SearchItemsParams {
+	return database.SearchItemsParams{
+		UserID:    userID,
+		Match:     sq.match,
+		HasStatus: sq.status != database.StatusAny,
+		Status:    sq.status,
+		FeedTitle: sq.feedTitle,
+		Limit:     defaultPageSize,
+		Offset:    int64(page) * defaultPageSize,
+	}
+}
@@ -17,6 +17,10 @@ type Server struct {
 	server *http.Server
 	log    *slog.Logger
 	worker *worker.Worker
+	// signupEnabled lets anyone create an account. Otherwise only the first
+	// account can be created.
+	signupEnabled bool
+	csrf          *http.CrossOriginProtection
 }
 
 func (s *Server) Close() error {
@@ -39,12 +43,14 @@ func (s *Server) ListenAndServe() error {
 	return nil
 }
 
-func New(ctx context.Context, port int, db *sql.DB, worker *worker.Worker, logger *slog.Logger) *Server {
+func New(ctx context.Context, port int, db *sql.DB, worker *worker.Worker, signupEnabled bool, logger *slog.Logger) *Server {
 	s := &Server{
-		db:     db,
-		port:   port,
-		log:    logger,
-		worker: worker,
+		db:            db,
+		port:          port,
+		log:           logger,
+		worker:        worker,
+		signupEnabled: signupEnabled,
+		csrf:          http.NewCrossOriginProtection(),
 	}
 
 	server := &http.Server{
@@ -0,0 +1,45 @@
+package worker
+
+// eventBufferSize is how many events a subscriber can fall behind by before
+// events are dropped for it.
+const eventBufferSize = 16
+
+// Event tells subscribers that unread counts may have changed.
+type Event struct {
+	// FeedID and NewItems are set when a refresh stored new items in the
+	// feed.
+	FeedID   int64
+	NewItems int
+	// UserID is set when the user changed the state of their items.
+	UserID int64
+}
+
+// Subscribe returns a channel of the events published from now on, and a
+// function that unsubscribes it. Events are dropped, rather than blocking the
+// publisher, for subscribers that fall behind.
+func (w *Worker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	w.subscribersMu.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.subscribersMu.Unlock()
+
+	return ch, func() {
+		w.subscribersMu.Lock()
+		delete(w.subscribers, ch)
+		w.subscribersMu.Unlock()
+	}
+}
+
+// Publish sends an event to every subscriber.
+func (w *Worker) Publish(event Event) {
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+
+	for ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/rss"
+)
+
+const (
+	maxExtractionsPerRefresh = 10
+	articleExtractionTimeout = time.Minute
+)
+
+// extractArticles downloads the original articles of the feed's newest items
+// that haven't been extracted yet and stores their main content, if any
+// subscriber fetches full articles. Items whose
+// extraction fails are marked as extracted with no content so they aren't
+// retried on every refresh.
+func (w *Worker) extractArticles(ctx context.Context, feed database.Feed, settings feedSettings) error {
+	if !settings.fetchFullContent {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, articleExtractionTimeout)
+	defer cancel()
+
+	logger := w.log.With("feed_id", feed.ID, "url", feed.URL)
+
+	items, err := database.New(w.db).ListItemsToExtract(
+		ctx,
+		database.ListItemsToExtractParams{FeedID: feed.ID, Limit: maxExtractionsPerRefresh},
+	)
+	if err != nil {
+		return fmt.Errorf("listing items to extract: %w", err)
+	}
+
+	for i, item := range items {
+		content, err := rss.ExtractArticle(ctx, item.Link)
+		if ctx.Err() != nil {
+			logger.Warn("Ran out of time extracting articles.", "remaining", len(items)-i)
+			return nil
+		}
+		if err != nil {
+			logger.Warn("Failed to extract article.", "item_id", item.ID, "link", item.Link, "error", err)
+		}
+
+		if err := w.storeExtractedContent(ctx, item.ID, content); err != nil {
+			return err
+		}
+	}
+
+	if len(items) > 0 {
+		logger.Info("Extracted articles.", "num_items", len(items))
+	}
+
+	return nil
+}
+
+func (w *Worker) storeExtractedContent(ctx context.Context, itemID int64, content string) error {
+	w.dbMu.Lock()
+	defer w.dbMu.Unlock()
+
+	if err := database.New(w.db).UpdateItemExtractedContent(
+		ctx,
+		database.UpdateItemExtractedContentParams{
+			ExtractedContent: content,
+			ExtractedAt:      sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			ID:               itemID,
+		},
+	); err != nil {
+		return fmt.Errorf("storing extracted content: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+const (
+	retentionInterval = time.Hour
+	// retentionBatchSize keeps the number of bound variables per statement well
+	// under SQLite's limit.
+	retentionBatchSize = 500
+)
+
+// RetentionPolicy decides which items are deleted. Starred items are always
+// kept. A zero value keeps items forever.
+type RetentionPolicy struct {
+	// ReadDays is how many days read items are kept after they are read.
+	ReadDays int64
+	// MaxItems is the most items kept per feed.
+	MaxItems int64
+}
+
+// forSubscription returns the policy a subscriber asked for, with their
+// overrides applied.
+func (p RetentionPolicy) forSubscription(sub database.ListSubscriptionSettingsRow) RetentionPolicy {
+	if sub.RetentionReadDays.Valid {
+		p.ReadDays = sub.RetentionReadDays.Int64
+	}
+	if sub.RetentionMaxItems.Valid {
+		p.MaxItems = sub.RetentionMaxItems.Int64
+	}
+	return p
+}
+
+// keepingMost returns the policy that deletes only what both p and other
+// delete: the longer of each limit, where zero keeps items forever.
+func (p RetentionPolicy) keepingMost(other RetentionPolicy) RetentionPolicy {
+	return RetentionPolicy{
+		ReadDays: longestLimit(p.ReadDays, other.ReadDays),
+		MaxItems: longestLimit(p.MaxItems, other.MaxItems),
+	}
+}
+
+func longestLimit(a, b int64) int64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	return max(a, b)
+}
+
+// enforceRetention deletes the items of every feed that fall outside the
+// retention policies of all of its subscribers. Their hashes are kept as
+// tombstones so the next refresh doesn't add them back.
+func (w *Worker) enforceRetention(ctx context.Context) error {
+	feeds, err := database.New(w.db).ListAllFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("listing feeds: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	var total int
+	for _, feed := range feeds {
+		settings, err := w.settingsFor(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("getting settings of feed %d: %w", feed.ID, err)
+		}
+
+		policy := settings.retention
+		if policy.ReadDays <= 0 && policy.MaxItems <= 0 {
+			continue
+		}
+
+		n, err := w.deleteItemsPastRetention(ctx, feed.ID, policy, now)
+		if err != nil {
+			return fmt.Errorf("enforcing retention for feed %d: %w", feed.ID, err)
+		}
+		total += n
+	}
+
+	if total > 0 {
+		w.log.Info("Deleted items past retention.", "num_items", total)
+	}
+
+	return nil
+}
+
+func (w *Worker) deleteItemsPastRetention(ctx context.Context, feedID int64, policy RetentionPolicy, now time.Time) (int, error) {
+	w.dbMu.Lock()
+	defer w.dbMu.Unlock()
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(w.db).WithTx(tx)
+
+	ids, err := q.ListItemsPastRetention(ctx, database.ListItemsPastRetentionParams{
+		FeedID:        feedID,
+		HasReadCutoff: policy.ReadDays > 0,
+		// read_at is set by CURRENT_TIMESTAMP, so it is compared in SQLite's
+		// format.
+		ReadCutoff:  now.AddDate(0, 0, -int(policy.ReadDays)).Format(time.DateTime),
+		HasMaxItems: policy.MaxItems > 0,
+		MaxItems:    policy.MaxItems,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("listing items past retention: %w", err)
+	}
+
+	for batch := range slices.Chunk(ids, retentionBatchSize) {
+		if err := q.CreateItemTombstones(ctx, batch); err != nil {
+			return 0, fmt.Errorf("creating tombstones: %w", err)
+		}
+
+		if err := q.DeleteItems(ctx, batch); err != nil {
+			return 0, fmt.Errorf("deleting items: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return len(ids), nil
+}
@@ -0,0 +1,270 @@
+package worker
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+func newTestWorker(t *testing.T, retention RetentionPolicy) *Worker {
+	t.Helper()
+
+	db, err := database.Init(t.Context(), fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return New(db, retention, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// retentionFixture is a feed with one subscriber, alice.
+type retentionFixture struct {
+	w      *Worker
+	q      *database.Queries
+	feedID int64
+	alice  int64
+}
+
+func newRetentionFixture(t *testing.T, retention RetentionPolicy) retentionFixture {
+	t.Helper()
+
+	w := newTestWorker(t, retention)
+	q := database.New(w.db)
+
+	feed, err := q.CreateFeed(t.Context(), database.CreateFeedParams{Title: "Feed", URL: "https://example.com/feed.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := retentionFixture{w: w, q: q, feedID: feed.ID}
+	f.alice = f.subscribe(t, "alice")
+
+	return f
+}
+
+func (f retentionFixture) subscribe(t *testing.T, username string) int64 {
+	t.Helper()
+
+	user, err := f.q.CreateUser(t.Context(), database.CreateUserParams{Username: username, PasswordHash: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.q.CreateSubscription(t.Context(), database.CreateSubscriptionParams{UserID: user.ID, FeedID: f.feedID, Title: "Feed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.q.CreateSubscriptionItemStates(t.Context(), database.CreateSubscriptionItemStatesParams{UserID: user.ID, FeedID: f.feedID}); err != nil {
+		t.Fatal(err)
+	}
+
+	return user.ID
+}
+
+func (f retentionFixture) addItem(t *testing.T, title string, publishedAt time.Time) int64 {
+	t.Helper()
+
+	id, err := f.q.CreateItem(t.Context(), database.CreateItemParams{
+		FeedID:      f.feedID,
+		Title:       title,
+		Categories:  "[]",
+		Hash:        title,
+		PublishedAt: publishedAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.q.CreateSubscriptionItemStates(t.Context(), database.CreateSubscriptionItemStatesParams{UserID: f.alice, FeedID: f.feedID}); err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+// read marks the item as read by the user at readAt.
+func (f retentionFixture) read(t *testing.T, userID, itemID int64, readAt time.Time) {
+	t.Helper()
+
+	if err := f.q.UpdateItemStatus(t.Context(), database.UpdateItemStatusParams{Status: database.StatusRead, ID: itemID, UserID: userID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.w.db.ExecContext(
+		t.Context(),
+		"UPDATE item_states SET read_at = ? WHERE user_id = ? AND item_id = ?",
+		readAt.UTC().Format(time.DateTime), userID, itemID,
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f retentionFixture) remainingItems(t *testing.T) []int64 {
+	t.Helper()
+
+	if err := f.w.enforceRetention(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := f.w.db.QueryContext(t.Context(), "SELECT id FROM items ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return ids
+}
+
+func TestRetentionReadDays(t *testing.T) {
+	f := newRetentionFixture(t, RetentionPolicy{ReadDays: 7})
+
+	now := time.Now()
+	longAgo := now.AddDate(0, 0, -30)
+
+	readLongAgo := f.addItem(t, "read long ago", now)
+	f.read(t, f.alice, readLongAgo, now.AddDate(0, 0, -10))
+
+	oldButJustRead := f.addItem(t, "old but just read", longAgo)
+	f.read(t, f.alice, oldButJustRead, now)
+
+	oldUnread := f.addItem(t, "old unread", longAgo)
+
+	oldStarred := f.addItem(t, "old starred", longAgo)
+	f.read(t, f.alice, oldStarred, longAgo)
+	if err := f.q.UpdateItemStarred(t.Context(), database.UpdateItemStarredParams{Starred: true, ID: oldStarred, UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int64{oldButJustRead, oldUnread, oldStarred}
+	if got := f.remainingItems(t); !slices.Equal(got, want) {
+		t.Errorf("remaining items = %v, want %v", got, want)
+	}
+}
+
+func TestRetentionReadDaysWaitsForEverySubscriber(t *testing.T) {
+	f := newRetentionFixture(t, RetentionPolicy{ReadDays: 7})
+
+	now := time.Now()
+	tenDaysAgo := now.AddDate(0, 0, -10)
+
+	readByBoth := f.addItem(t, "read by both", tenDaysAgo)
+	unreadByBob := f.addItem(t, "unread by bob", tenDaysAgo)
+	justReadByBob := f.addItem(t, "just read by bob", tenDaysAgo)
+
+	bob := f.subscribe(t, "bob")
+
+	for _, id := range []int64{readByBoth, unreadByBob, justReadByBob} {
+		f.read(t, f.alice, id, tenDaysAgo)
+	}
+	f.read(t, bob, readByBoth, tenDaysAgo)
+	f.read(t, bob, justReadByBob, now)
+
+	want := []int64{unreadByBob, justReadByBob}
+	if got := f.remainingItems(t); !slices.Equal(got, want) {
+		t.Errorf("remaining items = %v, want %v", got, want)
+	}
+}
+
+func TestReadAtFollowsStatus(t *testing.T) {
+	f := newRetentionFixture(t, RetentionPolicy{})
+	id := f.addItem(t, "item", time.Now())
+
+	readAt := func() sql.NullString {
+		t.Helper()
+		var v sql.NullString
+		if err := f.w.db.QueryRowContext(t.Context(), "SELECT read_at FROM item_states WHERE item_id = ?", id).Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	if v := readAt(); v.Valid {
+		t.Errorf("read_at of an unread item = %q, want NULL", v.String)
+	}
+
+	if err := f.q.UpdateItemStatus(t.Context(), database.UpdateItemStatusParams{Status: database.StatusRead, ID: id, UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+	if v := readAt(); !v.Valid {
+		t.Error("read_at of a read item is NULL")
+	}
+
+	if err := f.q.UpdateItemStatus(t.Context(), database.UpdateItemStatusParams{Status: database.StatusUnread, ID: id, UserID: f.alice}); err != nil {
+		t.Fatal(err)
+	}
+	if v := readAt(); v.Valid {
+		t.Errorf("read_at of an item marked unread again = %q, want NULL", v.String)
+	}
+}
+
+func TestRetentionKeepsWhatAnySubscriberWants(t *testing.T) {
+	limit := func(n int64) sql.NullInt64 { return sql.NullInt64{Int64: n, Valid: true} }
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		// bobReadDays and bobMaxItems override policy for bob, while alice
+		// keeps it.
+		bobReadDays, bobMaxItems sql.NullInt64
+		wantKept                 int
+	}{
+		{name: "default read days", policy: RetentionPolicy{ReadDays: 7}, wantKept: 0},
+		{name: "longer read days", policy: RetentionPolicy{ReadDays: 7}, bobReadDays: limit(30), wantKept: 2},
+		{name: "shorter read days", policy: RetentionPolicy{ReadDays: 30}, bobReadDays: limit(7), wantKept: 2},
+		{name: "read days kept forever", policy: RetentionPolicy{ReadDays: 7}, bobReadDays: limit(0), wantKept: 2},
+		{name: "default max items", policy: RetentionPolicy{MaxItems: 1}, wantKept: 1},
+		{name: "more max items", policy: RetentionPolicy{MaxItems: 1}, bobMaxItems: limit(2), wantKept: 2},
+		{name: "max items kept forever", policy: RetentionPolicy{MaxItems: 1}, bobMaxItems: limit(0), wantKept: 2},
+		{name: "fewer max items", policy: RetentionPolicy{}, bobMaxItems: limit(1), wantKept: 2},
+		{name: "both limits", policy: RetentionPolicy{ReadDays: 7}, bobReadDays: limit(30), bobMaxItems: limit(1), wantKept: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRetentionFixture(t, tt.policy)
+
+			tenDaysAgo := time.Now().AddDate(0, 0, -10)
+			older := f.addItem(t, "older", tenDaysAgo.Add(-time.Hour))
+			newer := f.addItem(t, "newer", tenDaysAgo)
+
+			bob := f.subscribe(t, "bob")
+			if err := f.q.UpdateSubscription(t.Context(), database.UpdateSubscriptionParams{
+				Title:             "Feed",
+				RetentionReadDays: tt.bobReadDays,
+				RetentionMaxItems: tt.bobMaxItems,
+				FeedID:            f.feedID,
+				UserID:            bob,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, id := range []int64{older, newer} {
+				f.read(t, f.alice, id, tenDaysAgo)
+				f.read(t, bob, id, tenDaysAgo)
+			}
+
+			if got := f.remainingItems(t); len(got) != tt.wantKept {
+				t.Errorf("remaining items = %v, want %d", got, tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/rss"
+)
+
+const (
+	// MinRefreshInterval is the shortest time between refreshes of a feed.
+	// Shorter user-set intervals aren't accepted.
+	MinRefreshInterval = 15 * time.Minute
+	maxRefreshInterval = 24 * time.Hour
+	maxBackoffExponent = 7
+)
+
+// refreshInterval returns how long to wait before refreshing feed again.
+//
+// After a failed refresh the interval starts at [MinRefreshInterval] and
+// doubles with each consecutive failure. Otherwise, if every subscriber set an
+// interval, the shortest one wins. If there is none the interval starts at
+// [MinRefreshInterval] and doubles for each consecutive refresh that found no
+// new items, so busy feeds are polled often and quiet ones rarely, but never
+// past the shortest interval a subscriber set. The feed's own TTL and the
+// response's Cache-Control max-age are treated as lower bounds, as is any
+// Retry-After sent with an error response.
+func refreshInterval(feed database.Feed, settings feedSettings, res rss.FetchResult, idleRefreshes int64, fetchErr error) time.Duration {
+	var interval time.Duration
+
+	switch {
+	case fetchErr != nil:
+		interval = MinRefreshInterval << min(feed.ConsecutiveFailures, maxBackoffExponent)
+		if settings.refreshInterval > 0 {
+			interval = max(interval, settings.refreshInterval)
+		}
+	case settings.refreshInterval > 0 && !settings.automaticRefresh:
+		interval = settings.refreshInterval
+	default:
+		interval = MinRefreshInterval << min(idleRefreshes, maxBackoffExponent)
+
+		ttl := res.TTL
+		if res.Feed == nil {
+			// Not modified, so use the TTL from the last full response.
+			ttl = time.Duration(feed.TTLSeconds) * time.Second
+		}
+		interval = max(interval, ttl, res.MaxAge)
+		if settings.refreshInterval > 0 {
+			interval = min(interval, settings.refreshInterval)
+		}
+	}
+
+	var httpErr *rss.HTTPError
+	if errors.As(fetchErr, &httpErr) {
+		interval = max(interval, httpErr.RetryAfter)
+	}
+
+	return min(max(interval, MinRefreshInterval), maxRefreshInterval)
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+	"github.com/ethansaxenian/rss/rss"
+	"github.com/mmcdole/gofeed"
+)
+
+func TestRefreshInterval(t *testing.T) {
+	fetched := rss.FetchResult{Feed: &gofeed.Feed{}}
+
+	tests := []struct {
+		name          string
+		settings      feedSettings
+		idleRefreshes int64
+		fetchErr      error
+		want          time.Duration
+	}{
+		{
+			name:          "automatic",
+			settings:      feedSettings{automaticRefresh: true},
+			idleRefreshes: 2,
+			want:          4 * MinRefreshInterval,
+		},
+		{
+			name:          "set by every subscriber",
+			settings:      feedSettings{refreshInterval: 2 * time.Hour},
+			idleRefreshes: 5,
+			want:          2 * time.Hour,
+		},
+		{
+			name:          "automatic for some subscribers",
+			settings:      feedSettings{refreshInterval: 2 * time.Hour, automaticRefresh: true},
+			idleRefreshes: 1,
+			want:          2 * MinRefreshInterval,
+		},
+		{
+			name:          "automatic is capped by a set interval",
+			settings:      feedSettings{refreshInterval: 2 * time.Hour, automaticRefresh: true},
+			idleRefreshes: 5,
+			want:          2 * time.Hour,
+		},
+		{
+			name:     "failed",
+			settings: feedSettings{refreshInterval: 20 * time.Minute},
+			fetchErr: errors.New("failed"), //nolint:err113
+			want:     2 * MinRefreshInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := database.Feed{ConsecutiveFailures: 1}
+			if got := refreshInterval(feed, tt.settings, fetched, tt.idleRefreshes, tt.fetchErr); got != tt.want {
+				t.Errorf("refreshInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethansaxenian/rss/database"
+)
+
+// feedSettings are the settings a feed is refreshed and kept with. Each
+// subscriber picks their own, so they are combined to give every subscriber
+// at least what they asked for: the feed is refreshed as often and its items
+// are kept as long as any of them wants.
+type feedSettings struct {
+	// refreshInterval is the shortest interval a subscriber set, or zero if
+	// none did.
+	refreshInterval time.Duration
+	// automaticRefresh is set if a subscriber left the interval to the
+	// refresh schedule, in which case refreshInterval only caps it.
+	automaticRefresh bool
+	// fetchFullContent is set if any subscriber fetches full articles.
+	fetchFullContent bool
+	retention        RetentionPolicy
+}
+
+// settingsFor combines the settings of feedID's subscribers, using
+// w.retention for those who didn't set a retention policy.
+func (w *Worker) settingsFor(ctx context.Context, feedID int64) (feedSettings, error) {
+	subs, err := database.New(w.db).ListSubscriptionSettings(ctx, feedID)
+	if err != nil {
+		return feedSettings{}, fmt.Errorf("listing subscription settings: %w", err)
+	}
+
+	var settings feedSettings
+	for i, sub := range subs {
+		if sub.RefreshIntervalMinutes.Valid {
+			interval := time.Duration(sub.RefreshIntervalMinutes.Int64) * time.Minute
+			if settings.refreshInterval == 0 || interval < settings.refreshInterval {
+				settings.refreshInterval = interval
+			}
+		} else {
+			settings.automaticRefresh = true
+		}
+
+		settings.fetchFullContent = settings.fetchFullContent || sub.FetchFullContent
+
+		policy := w.retention.forSubscription(sub)
+		if i == 0 {
+			settings.retention = policy
+		} else {
+			settings.retention = settings.retention.keepingMost(policy)
+		}
+	}
+
+	return settings, nil
+}
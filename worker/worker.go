@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -14,45 +15,77 @@ import (
 )
 
 const (
-	refreshLoopInterval     = 60 * time.Minute
+	schedulerInterval       = time.Minute
 	maxConcurrentRefreshes  = 5
 	feedRefreshTimeout      = 15 * time.Second
 	refreshThrottleInverval = 10 * time.Minute
+	feedRefreshQueueSize    = 100
 )
 
 type Worker struct {
-	db          *sql.DB
-	dbMu        sync.Mutex
-	refreshChan chan struct{}
-	log         *slog.Logger
+	db              *sql.DB
+	dbMu            sync.Mutex
+	refreshChan     chan struct{}
+	feedRefreshChan chan int64
+	retention       RetentionPolicy
+	log             *slog.Logger
+	subscribersMu   sync.Mutex
+	subscribers     map[chan Event]struct{}
 }
 
-func New(db *sql.DB, logger *slog.Logger) *Worker {
+func New(db *sql.DB, retention RetentionPolicy, logger *slog.Logger) *Worker {
 	return &Worker{
-		db:          db,
-		refreshChan: make(chan struct{}, 1),
-		log:         logger,
+		db:              db,
+		refreshChan:     make(chan struct{}, 1),
+		feedRefreshChan: make(chan int64, feedRefreshQueueSize),
+		retention:       retention,
+		log:             logger,
+		subscribers:     make(map[chan Event]struct{}),
 	}
 }
 
+// RefreshAll queues a refresh of every feed, unless one is already queued.
 func (w *Worker) RefreshAll() {
-	w.refreshChan <- struct{}{}
+	select {
+	case w.refreshChan <- struct{}{}:
+	default:
+	}
+}
+
+// RefreshFeed queues a refresh of a single feed. It doesn't block callers, so
+// the refresh is dropped if the queue is full: the feed is still refreshed
+// when it is next due.
+func (w *Worker) RefreshFeed(feedID int64) {
+	select {
+	case w.feedRefreshChan <- feedID:
+	default:
+		w.log.Warn("Feed refresh queue is full. Dropping refresh.", "feed_id", feedID)
+	}
 }
 
 func (w *Worker) RunLoop(ctx context.Context) {
 	w.log.Info("Starting worker")
-	ticker := time.Tick(refreshLoopInterval)
+	ticker := time.Tick(schedulerInterval)
+	retentionTicker := time.Tick(retentionInterval)
 
 	for {
 		select {
+		case <-retentionTicker:
+			if err := w.enforceRetention(ctx); err != nil {
+				w.log.Error("Error enforcing retention", "error", err)
+			}
 		case <-ticker:
-			if err := w.refreshFeeds(ctx); err != nil {
+			if err := w.refreshFeeds(ctx, true); err != nil {
 				w.log.Error("Error refreshing feeds", "error", err)
 			}
 		case <-w.refreshChan:
-			if err := w.refreshFeeds(ctx); err != nil {
+			if err := w.refreshFeeds(ctx, false); err != nil {
 				w.log.Error("Error refreshing feeds", "error", err)
 			}
+		case feedID := <-w.feedRefreshChan:
+			if err := w.refreshFeedByID(ctx, feedID); err != nil {
+				w.log.Error("Error refreshing feed", "feed_id", feedID, "error", err)
+			}
 		case <-ctx.Done():
 			w.log.Info("Context cancelled, exiting.")
 			return
@@ -60,12 +93,21 @@ func (w *Worker) RunLoop(ctx context.Context) {
 	}
 }
 
-func (w *Worker) refreshFeeds(ctx context.Context) error {
+// refreshFeeds refreshes every feed, or only the feeds whose next scheduled
+// refresh has passed if onlyDue is set.
+func (w *Worker) refreshFeeds(ctx context.Context, onlyDue bool) error {
 	var eg errgroup.Group
 	eg.SetLimit(maxConcurrentRefreshes)
 
 	q := database.New(w.db)
-	feeds, err := q.ListFeeds(ctx)
+
+	var feeds []database.Feed
+	var err error
+	if onlyDue {
+		feeds, err = q.ListFeedsDueForRefresh(ctx)
+	} else {
+		feeds, err = q.ListAllFeeds(ctx)
+	}
 	if err != nil {
 		return fmt.Errorf("listing feeds: %w", err)
 	}
@@ -74,11 +116,20 @@ func (w *Worker) refreshFeeds(ctx context.Context) error {
 
 	for _, feed := range feeds {
 		eg.Go(func() error {
+			settings, err := w.settingsFor(ctx, feed.ID)
+			if err != nil {
+				w.log.Error("Error getting feed settings", "feed_id", feed.ID, "url", feed.URL, "error", err)
+				return nil
+			}
+
 			feedCtx, cancel := context.WithTimeout(ctx, feedRefreshTimeout)
 			defer cancel()
-			if err = w.refreshFeed(feedCtx, feed); err != nil {
+			if err := w.refreshFeed(feedCtx, feed, settings); err != nil {
 				w.log.Error("Error refreshing feed", "feed_id", feed.ID, "url", feed.URL, "error", err)
 			}
+			if err := w.extractArticles(ctx, feed, settings); err != nil {
+				w.log.Error("Error extracting articles", "feed_id", feed.ID, "url", feed.URL, "error", err)
+			}
 			return nil
 		})
 	}
@@ -90,44 +141,214 @@ func (w *Worker) refreshFeeds(ctx context.Context) error {
 	return nil
 }
 
-func (w *Worker) refreshFeed(ctx context.Context, feed database.Feed) error {
+func (w *Worker) refreshFeedByID(ctx context.Context, feedID int64) error {
+	q := database.New(w.db)
+	feed, err := q.GetFeed(ctx, feedID)
+	if err != nil {
+		return fmt.Errorf("getting feed: %w", err)
+	}
+
+	settings, err := w.settingsFor(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("getting feed settings: %w", err)
+	}
+
+	feedCtx, cancel := context.WithTimeout(ctx, feedRefreshTimeout)
+	defer cancel()
+
+	if err := w.refreshFeed(feedCtx, feed, settings); err != nil {
+		return err
+	}
+
+	return w.extractArticles(ctx, feed, settings)
+}
+
+func (w *Worker) refreshFeed(ctx context.Context, feed database.Feed, settings feedSettings) error {
 	logger := w.log.With("feed_id", feed.ID, "url", feed.URL)
 
 	now := time.Now().UTC()
 
 	if feed.LastRefreshedAt.Valid && feed.LastRefreshedAt.Time.Add(refreshThrottleInverval).After(now) {
-		logger.Warn("Refresh triggered too quickly. Try again later.", "can_refresh_at", feed.LastRefreshedAt.Time.Add(refreshThrottleInverval).Local())
+		next := feed.LastRefreshedAt.Time.Add(refreshThrottleInverval)
+		logger.Warn("Refresh triggered too quickly. Try again later.", "can_refresh_at", next.Local())
+
+		// Otherwise a feed that is due stays due, and is throttled again on
+		// every tick until it can be refreshed. A later refresh that is
+		// already scheduled is kept.
+		if !feed.NextRefreshAt.Valid || feed.NextRefreshAt.Time.Before(next) {
+			if err := w.scheduleRefresh(ctx, feed, next); err != nil {
+				logger.Error("Failed to reschedule throttled refresh.", "error", err)
+			}
+		}
 		return nil
 	}
 
 	logger.Info("Refreshing feed.")
 
-	res, err := rss.FetchFeed(ctx, feed.URL)
+	res, numNewItems, err := w.updateFeed(ctx, feed, logger)
+
+	idleRefreshes := feed.IdleRefreshes
+	switch {
+	case err != nil:
+	case numNewItems > 0:
+		idleRefreshes = 0
+	default:
+		idleRefreshes++
+	}
+
+	interval := refreshInterval(feed, settings, res, idleRefreshes, err)
+	if recordErr := w.recordRefresh(ctx, feed.ID, res, err, now.Add(interval), idleRefreshes); recordErr != nil {
+		logger.Error("Failed to record refresh.", "error", recordErr)
+	}
+
 	if err != nil {
-		return fmt.Errorf("fetching feed URL: %w", err)
+		logger.Warn("Backing off after failed refresh.", "consecutive_failures", feed.ConsecutiveFailures+1, "interval", interval)
+		return err
+	}
+
+	logger.Info("Scheduled next refresh.", "interval", interval)
+
+	if numNewItems > 0 {
+		w.Publish(Event{FeedID: feed.ID, NewItems: numNewItems})
+	}
+
+	return nil
+}
+
+// updateFeed fetches the feed and stores any new or updated items, returning
+// the fetch result and the number of new items.
+func (w *Worker) updateFeed(ctx context.Context, feed database.Feed, logger *slog.Logger) (rss.FetchResult, int, error) {
+	res, err := rss.FetchFeedConditional(ctx, feed.URL, feed.Etag.String, feed.LastModified.String)
+	if err != nil {
+		return rss.FetchResult{}, 0, fmt.Errorf("fetching feed URL: %w", err)
 	}
 
 	w.dbMu.Lock()
 	defer w.dbMu.Unlock()
 
+	if res.NotModified {
+		if err := database.New(w.db).RecordFeedNotModified(ctx, feed.ID); err != nil {
+			return res, 0, fmt.Errorf("recording not modified response: %w", err)
+		}
+
+		logger.Info("Feed not modified.", "bytes_saved", feed.LastContentLength)
+		return res, 0, nil
+	}
+
 	tx, err := w.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("starting transaction: %w", err)
+		return res, 0, fmt.Errorf("starting transaction: %w", err)
 	}
 	defer tx.Rollback() //nolint:errcheck
 
 	q := database.New(w.db).WithTx(tx)
 
-	numNewItems, numUpdatedItems, err := rss.UpdateFeedItems(ctx, q, feed.ID, res, logger)
+	numNewItems, numUpdatedItems, err := rss.UpdateFeedItems(ctx, q, feed.ID, res.Feed, logger)
 	if err != nil {
-		return fmt.Errorf("updating feed items: %w", err)
+		return res, 0, fmt.Errorf("updating feed items: %w", err)
+	}
+
+	if err := q.UpdateFeedCacheHeaders(
+		ctx,
+		database.UpdateFeedCacheHeadersParams{
+			Etag:              sql.NullString{String: res.ETag, Valid: res.ETag != ""},
+			LastModified:      sql.NullString{String: res.LastModified, Valid: res.LastModified != ""},
+			LastContentLength: res.ContentLength,
+			ID:                feed.ID,
+		},
+	); err != nil {
+		return res, 0, fmt.Errorf("updating feed cache headers: %w", err)
+	}
+
+	if err := q.UpdateFeedTTL(ctx, database.UpdateFeedTTLParams{TTLSeconds: int64(res.TTL.Seconds()), ID: feed.ID}); err != nil {
+		return res, 0, fmt.Errorf("updating feed TTL: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("committing transaction: %w", err)
+		return res, 0, fmt.Errorf("committing transaction: %w", err)
 	}
 
 	logger.Info("Successfully refreshed feed.", "new_items", numNewItems, "updated_items", numUpdatedItems)
 
+	return res, numNewItems, nil
+}
+
+// scheduleRefresh sets when feed is next refreshed, without recording a
+// refresh.
+func (w *Worker) scheduleRefresh(ctx context.Context, feed database.Feed, next time.Time) error {
+	w.dbMu.Lock()
+	defer w.dbMu.Unlock()
+
+	if err := database.New(w.db).UpdateFeedSchedule(
+		ctx,
+		database.UpdateFeedScheduleParams{
+			NextRefreshAt: sql.NullTime{Time: next, Valid: true},
+			IdleRefreshes: feed.IdleRefreshes,
+			ID:            feed.ID,
+		},
+	); err != nil {
+		return fmt.Errorf("updating feed schedule: %w", err)
+	}
+
+	return nil
+}
+
+// recordRefresh stores the outcome of a refresh and schedules the next one.
+func (w *Worker) recordRefresh(ctx context.Context, feedID int64, res rss.FetchResult, refreshErr error, next time.Time, idleRefreshes int64) error {
+	w.dbMu.Lock()
+	defer w.dbMu.Unlock()
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := database.New(w.db).WithTx(tx)
+
+	if refreshErr != nil {
+		var status sql.NullInt64
+		var httpErr *rss.HTTPError
+		if errors.As(refreshErr, &httpErr) {
+			status = sql.NullInt64{Int64: int64(httpErr.StatusCode), Valid: true}
+		}
+
+		if err := q.RecordFeedFailure(
+			ctx,
+			database.RecordFeedFailureParams{
+				LastError:      sql.NullString{String: refreshErr.Error(), Valid: true},
+				LastHTTPStatus: status,
+				ID:             feedID,
+			},
+		); err != nil {
+			return fmt.Errorf("recording feed failure: %w", err)
+		}
+	} else {
+		if err := q.RecordFeedSuccess(
+			ctx,
+			database.RecordFeedSuccessParams{
+				LastHTTPStatus: sql.NullInt64{Int64: int64(res.StatusCode), Valid: res.StatusCode != 0},
+				ID:             feedID,
+			},
+		); err != nil {
+			return fmt.Errorf("recording feed success: %w", err)
+		}
+	}
+
+	if err := q.UpdateFeedSchedule(
+		ctx,
+		database.UpdateFeedScheduleParams{
+			NextRefreshAt: sql.NullTime{Time: next, Valid: true},
+			IdleRefreshes: idleRefreshes,
+			ID:            feedID,
+		},
+	); err != nil {
+		return fmt.Errorf("updating feed schedule: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
 	return nil
 }